@@ -0,0 +1,227 @@
+// Package client is a Go client for the MetaGrid services: Traffic, Parking
+// and Weather.
+//
+// A Client reaches each service at the host Traefik routes to it, such as
+// http://traffic.localhost, unless WithBaseURL says otherwise:
+//
+//	c, err := client.New(client.WithAuth(client.BearerToken(token)))
+//	if err != nil {
+//		return err
+//	}
+//	id, err := c.Traffic.Create(ctx, client.TrafficLightInput{Location: "Main St", Color: "red"})
+//	...
+//	for light, err := range c.Traffic.All(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(light.Location, light.Color)
+//	}
+//
+// Every call takes a context and returns an *APIError when the service
+// answers with an error status; errors.Is matches it against ErrNotFound and
+// the other sentinel errors.
+//
+// The package follows semantic versioning: Version is the version of this
+// copy, and within a major version calls and fields are only added, never
+// changed or removed.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Version is the version of the client, sent in the User-Agent header.
+const Version = "1.0.0"
+
+// Services, as named to WithBaseURL.
+const (
+	ServiceTraffic = "traffic"
+	ServiceParking = "parking"
+	ServiceWeather = "weather"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Client calls the MetaGrid services. It is safe for concurrent use.
+type Client struct {
+	Traffic *TrafficService
+	Parking *ParkingService
+	Weather *WeatherService
+
+	httpClient *http.Client
+	auth       Authenticator
+	userAgent  string
+	baseURLs   map[string]*url.URL
+}
+
+// Option configures a Client.
+type Option func(*Client) error
+
+// New returns a Client configured by opts.
+func New(opts ...Option) (*Client, error) {
+	c := &Client{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		userAgent:  "metagrid-client-go/" + Version,
+		baseURLs:   make(map[string]*url.URL),
+	}
+	for _, service := range []string{ServiceTraffic, ServiceParking, ServiceWeather} {
+		c.baseURLs[service] = &url.URL{Scheme: "http", Host: service + ".localhost"}
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	c.Traffic = &TrafficService{c}
+	c.Parking = &ParkingService{c}
+	c.Weather = &WeatherService{c}
+	return c, nil
+}
+
+// WithHTTPClient sends requests with hc instead of a client with a 30 second
+// timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		c.httpClient = hc
+		return nil
+	}
+}
+
+// WithTransport sends requests through rt, for example to add retries,
+// tracing or service discovery, keeping the client's timeout.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) error {
+		hc := *c.httpClient
+		hc.Transport = rt
+		c.httpClient = &hc
+		return nil
+	}
+}
+
+// WithBaseURL reaches service at rawURL, such as http://localhost:5050.
+func WithBaseURL(service, rawURL string) Option {
+	return func(c *Client) error {
+		if _, ok := c.baseURLs[service]; !ok {
+			return fmt.Errorf("unknown service %q", service)
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("base URL of %s: %w", service, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("base URL of %s must be absolute: %q", service, rawURL)
+		}
+		c.baseURLs[service] = u
+		return nil
+	}
+}
+
+// WithAuth has auth authenticate every request.
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) error {
+		c.auth = auth
+		return nil
+	}
+}
+
+// WithUserAgent prefixes the User-Agent header with product, such as
+// "web2/1.4".
+func WithUserAgent(product string) Option {
+	return func(c *Client) error {
+		c.userAgent = product + " " + c.userAgent
+		return nil
+	}
+}
+
+// Authenticator adds credentials to a request before it is sent.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator.
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken sends a token in the Authorization header.
+type BearerToken string
+
+func (t BearerToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+	return nil
+}
+
+// newRequest builds a request to path on service, encoding body as JSON
+// unless it is nil. The body is held in memory so the request can be sent
+// again by a retrying transport.
+func (c *Client) newRequest(ctx context.Context, service, method, path string, query url.Values, body any) (*http.Request, error) {
+	u := c.baseURLs[service].JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// do sends req and decodes a successful response's JSON body into out,
+// unless out is nil. It returns an *APIError for a response with a status of
+// 300 or more. The response is returned with its body closed, for its
+// headers.
+func (c *Client) do(req *http.Request, service string, out any) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return resp, newAPIError(service, req, resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("decoding %s response from %s: %w", req.Method, req.URL.Path, err)
+		}
+	} else {
+		// Read to the end so the connection can be reused.
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	}
+	return resp, nil
+}
+
+// call builds and sends a request in one step.
+func (c *Client) call(ctx context.Context, service, method, path string, query url.Values, body, out any) (*http.Response, error) {
+	req, err := c.newRequest(ctx, service, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req, service, out)
+}
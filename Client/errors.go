@@ -0,0 +1,71 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors an *APIError matches with errors.Is, by status.
+var (
+	ErrBadRequest    = errors.New("bad request")           // 400
+	ErrUnauthorized  = errors.New("unauthorized")          // 401 and 403
+	ErrNotFound      = errors.New("not found")             // 404
+	ErrConflict      = errors.New("conflict")              // 409, such as acknowledging a resolved alert
+	ErrUnprocessable = errors.New("unprocessable")         // 422, such as a forecast without enough history
+	ErrUnavailable   = errors.New("service unavailable")   // 502, 503 and 504
+	ErrServer        = errors.New("internal server error") // any other 5xx
+)
+
+// APIError is a response with an error status from a service.
+type APIError struct {
+	Service    string
+	Method     string
+	Path       string
+	StatusCode int
+	// Message is the service's explanation, the body of the response.
+	Message string
+	// RequestID identifies the request in the service's logs and traces.
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s %s: %d %s", e.Service, e.Method, e.Path, e.StatusCode, message)
+}
+
+// Is matches e against the sentinel error for its status.
+func (e *APIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return target == ErrUnauthorized
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusUnprocessableEntity:
+		return target == ErrUnprocessable
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return target == ErrUnavailable
+	}
+	return e.StatusCode >= 500 && target == ErrServer
+}
+
+func newAPIError(service string, req *http.Request, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &APIError{
+		Service:    service,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+}
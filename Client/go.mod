@@ -0,0 +1,3 @@
+module metagrid/client
+
+go 1.23.3
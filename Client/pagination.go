@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultPageSize is the page size All iterates with.
+const defaultPageSize = 100
+
+// ListOptions selects a page of a list ordered by id.
+type ListOptions struct {
+	// Limit is the most items in the page, up to 1000. Zero asks for the
+	// whole list in one page.
+	Limit int
+	// After starts the page after the item with this id.
+	After int
+}
+
+func (o *ListOptions) values() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.After > 0 {
+		query.Set("after", strconv.Itoa(o.After))
+	}
+	return query
+}
+
+// Page is part of a list.
+type Page[T any] struct {
+	Items []T
+	// Next selects the page that follows, or is nil on the last page.
+	Next *ListOptions
+}
+
+// nextPage reads the options of the next page from the response's Link
+// header.
+func nextPage(resp *http.Response) *ListOptions {
+	for _, header := range resp.Header.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.Contains(params, `rel="next"`) {
+				continue
+			}
+			u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+			if err != nil {
+				continue
+			}
+			limit, _ := strconv.Atoi(u.Query().Get("limit"))
+			after, _ := strconv.Atoi(u.Query().Get("after"))
+			return &ListOptions{Limit: limit, After: after}
+		}
+	}
+	return nil
+}
+
+// all iterates over every item of a list, fetching it a page at a time with
+// list as the iteration reaches each page. A failed fetch is yielded as the
+// last element.
+func all[T any](ctx context.Context, list func(context.Context, *ListOptions) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		opts := &ListOptions{Limit: defaultPageSize}
+		for opts != nil {
+			page, err := list(ctx, opts)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			opts = page.Next
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ParkingSpot is a parking spot of the Parking service.
+type ParkingSpot struct {
+	ID           int       `json:"id"`
+	Location     string    `json:"location"`
+	Availability bool      `json:"availability"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ParkingSpotInput is a parking spot to create.
+type ParkingSpotInput struct {
+	Location     string `json:"location"`
+	Availability bool   `json:"availability"`
+}
+
+// ParkingService calls the Parking service.
+type ParkingService struct {
+	c *Client
+}
+
+// List returns a page of parking spots.
+func (s *ParkingService) List(ctx context.Context, opts *ListOptions) (*Page[ParkingSpot], error) {
+	var spots []ParkingSpot
+	resp, err := s.c.call(ctx, ServiceParking, http.MethodGet, "/parking", opts.values(), nil, &spots)
+	if err != nil {
+		return nil, err
+	}
+	if spots == nil {
+		spots = []ParkingSpot{}
+	}
+	return &Page[ParkingSpot]{Items: spots, Next: nextPage(resp)}, nil
+}
+
+// All iterates over every parking spot.
+func (s *ParkingService) All(ctx context.Context) iter.Seq2[ParkingSpot, error] {
+	return all(ctx, s.List)
+}
+
+// Get returns a parking spot.
+func (s *ParkingService) Get(ctx context.Context, id int) (*ParkingSpot, error) {
+	var spot ParkingSpot
+	if _, err := s.c.call(ctx, ServiceParking, http.MethodGet, "/parking/"+strconv.Itoa(id), nil, nil, &spot); err != nil {
+		return nil, err
+	}
+	return &spot, nil
+}
+
+// Create adds a parking spot and returns its id.
+func (s *ParkingService) Create(ctx context.Context, input ParkingSpotInput) (int, error) {
+	resp, err := s.c.call(ctx, ServiceParking, http.MethodPost, "/parking", nil, input, nil)
+	if err != nil {
+		return 0, err
+	}
+	return createdID(resp)
+}
+
+// SetAvailability marks a parking spot available or not.
+func (s *ParkingService) SetAvailability(ctx context.Context, id int, available bool) error {
+	query := url.Values{"availability": {strconv.FormatBool(available)}}
+	_, err := s.c.call(ctx, ServiceParking, http.MethodPut, "/parking/"+strconv.Itoa(id), query, nil, nil)
+	return err
+}
+
+// Delete removes a parking spot. Deleting one that does not exist succeeds.
+func (s *ParkingService) Delete(ctx context.Context, id int) error {
+	_, err := s.c.call(ctx, ServiceParking, http.MethodDelete, "/parking/"+strconv.Itoa(id), nil, nil, nil)
+	return err
+}
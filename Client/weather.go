@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Units is a unit system the Weather service converts values to. The zero
+// value leaves the choice to the service, which uses metric.
+type Units string
+
+const (
+	Metric   Units = "metric"   // °C, km/h, mm
+	Imperial Units = "imperial" // °F, mph, in
+	SI       Units = "si"       // K, m/s
+)
+
+// Alert states, as passed to ListAlerts.
+const (
+	AlertPending      = "pending"
+	AlertFiring       = "firing"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+)
+
+// WeatherEntry is a temperature observation at a location.
+type WeatherEntry struct {
+	ID              int       `json:"id"`
+	Location        string    `json:"location"`
+	Temperature     float64   `json:"temperature"`
+	TemperatureUnit string    `json:"temperature_unit,omitempty"`
+	Description     string    `json:"description"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// WeatherEntryInput is a weather entry to create. The temperature is in the
+// units the entry is created with.
+type WeatherEntryInput struct {
+	Location    string  `json:"location"`
+	Temperature float64 `json:"temperature"`
+	Description string  `json:"description"`
+}
+
+// WeatherEntryUpdate replaces the temperature and description of an entry.
+type WeatherEntryUpdate struct {
+	Temperature float64 `json:"temperature"`
+	Description string  `json:"description"`
+}
+
+// WeatherAlert is an alert raised by one of the Weather service's rules.
+type WeatherAlert struct {
+	ID             int        `json:"id"`
+	RuleID         int        `json:"rule_id"`
+	RuleName       string     `json:"rule_name"`
+	StationID      string     `json:"station_id"`
+	State          string     `json:"state"`
+	Severity       string     `json:"severity"`
+	Message        string     `json:"message"`
+	Metric         string     `json:"metric"`
+	Value          float64    `json:"value"`
+	Unit           string     `json:"unit"`
+	StartedAt      time.Time  `json:"started_at"`
+	FiredAt        *time.Time `json:"fired_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ForecastOptions narrows a forecast. Zero fields take the service's
+// defaults: temperature, 6 hours and a 95% interval.
+type ForecastOptions struct {
+	Metric string
+	Hours  int
+	// Level is the confidence level of the prediction interval: 80, 90, 95
+	// or 99.
+	Level int
+	Units Units
+}
+
+// SeriesPoint is an observed hourly average.
+type SeriesPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// ForecastPoint is a predicted hourly value and its prediction interval.
+type ForecastPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Lower float64   `json:"lower"`
+	Upper float64   `json:"upper"`
+}
+
+// WeatherForecast predicts a metric at a station for the next hours, with the
+// last day observed before it.
+type WeatherForecast struct {
+	StationID string `json:"station_id"`
+	Metric    string `json:"metric"`
+	Unit      string `json:"unit"`
+	Model     string `json:"model"`
+	Params    struct {
+		Alpha float64 `json:"alpha"`
+		Beta  float64 `json:"beta"`
+		Gamma float64 `json:"gamma,omitempty"`
+	} `json:"params"`
+	Level         int             `json:"level"`
+	HistoryPoints int             `json:"history_points"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Observed      []SeriesPoint   `json:"observed"`
+	Forecast      []ForecastPoint `json:"forecast"`
+	// Backtest measures the model on the history; nil when the history is too
+	// short.
+	Backtest *struct {
+		Origins  int      `json:"origins"`
+		Horizon  int      `json:"horizon"`
+		MAE      float64  `json:"mae"`
+		RMSE     float64  `json:"rmse"`
+		MAPE     *float64 `json:"mape,omitempty"`
+		Coverage float64  `json:"coverage"`
+	} `json:"backtest,omitempty"`
+}
+
+// WeatherService calls the Weather service. Values are converted to the units
+// given to each call.
+type WeatherService struct {
+	c *Client
+}
+
+func unitsQuery(units Units) url.Values {
+	query := url.Values{}
+	if units != "" {
+		query.Set("units", string(units))
+	}
+	return query
+}
+
+// ListEntries returns a page of weather entries.
+func (s *WeatherService) ListEntries(ctx context.Context, units Units, opts *ListOptions) (*Page[WeatherEntry], error) {
+	query := opts.values()
+	if units != "" {
+		query.Set("units", string(units))
+	}
+	var entries []WeatherEntry
+	resp, err := s.c.call(ctx, ServiceWeather, http.MethodGet, "/weather", query, nil, &entries)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []WeatherEntry{}
+	}
+	return &Page[WeatherEntry]{Items: entries, Next: nextPage(resp)}, nil
+}
+
+// AllEntries iterates over every weather entry.
+func (s *WeatherService) AllEntries(ctx context.Context, units Units) iter.Seq2[WeatherEntry, error] {
+	return all(ctx, func(ctx context.Context, opts *ListOptions) (*Page[WeatherEntry], error) {
+		return s.ListEntries(ctx, units, opts)
+	})
+}
+
+// GetEntry returns a weather entry. Observations without a temperature are
+// not weather entries and are not found.
+func (s *WeatherService) GetEntry(ctx context.Context, id int, units Units) (*WeatherEntry, error) {
+	var entry WeatherEntry
+	if _, err := s.c.call(ctx, ServiceWeather, http.MethodGet, "/weather/"+strconv.Itoa(id), unitsQuery(units), nil, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CreateEntry adds a weather entry and returns its id.
+func (s *WeatherService) CreateEntry(ctx context.Context, input WeatherEntryInput, units Units) (int, error) {
+	resp, err := s.c.call(ctx, ServiceWeather, http.MethodPost, "/weather", unitsQuery(units), input, nil)
+	if err != nil {
+		return 0, err
+	}
+	return createdID(resp)
+}
+
+// UpdateEntry replaces the temperature and description of a weather entry.
+func (s *WeatherService) UpdateEntry(ctx context.Context, id int, update WeatherEntryUpdate, units Units) error {
+	_, err := s.c.call(ctx, ServiceWeather, http.MethodPut, "/weather/"+strconv.Itoa(id), unitsQuery(units), update, nil)
+	return err
+}
+
+// DeleteEntry removes a weather entry.
+func (s *WeatherService) DeleteEntry(ctx context.Context, id int) error {
+	_, err := s.c.call(ctx, ServiceWeather, http.MethodDelete, "/weather/"+strconv.Itoa(id), nil, nil, nil)
+	return err
+}
+
+// Forecast predicts a metric at location, a station id or name. It fails
+// with ErrNotFound for an unknown location and ErrUnprocessable when the
+// station has too little history.
+func (s *WeatherService) Forecast(ctx context.Context, location string, opts *ForecastOptions) (*WeatherForecast, error) {
+	query := url.Values{"location": {location}}
+	if opts != nil {
+		if opts.Units != "" {
+			query.Set("units", string(opts.Units))
+		}
+		if opts.Metric != "" {
+			query.Set("metric", opts.Metric)
+		}
+		if opts.Hours > 0 {
+			query.Set("hours", strconv.Itoa(opts.Hours))
+		}
+		if opts.Level > 0 {
+			query.Set("level", strconv.Itoa(opts.Level))
+		}
+	}
+	var forecast WeatherForecast
+	if _, err := s.c.call(ctx, ServiceWeather, http.MethodGet, "/weather/forecast", query, nil, &forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// ListAlerts returns the latest 500 alerts in any of states, by default those
+// firing or acknowledged, newest first.
+func (s *WeatherService) ListAlerts(ctx context.Context, units Units, states ...string) ([]WeatherAlert, error) {
+	query := unitsQuery(units)
+	if len(states) > 0 {
+		query.Set("state", strings.Join(states, ","))
+	}
+	var alerts []WeatherAlert
+	if _, err := s.c.call(ctx, ServiceWeather, http.MethodGet, "/alerts", query, nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetAlert returns an alert.
+func (s *WeatherService) GetAlert(ctx context.Context, id int, units Units) (*WeatherAlert, error) {
+	var alert WeatherAlert
+	if _, err := s.c.call(ctx, ServiceWeather, http.MethodGet, "/alerts/"+strconv.Itoa(id), unitsQuery(units), nil, &alert); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// AcknowledgeAlert acknowledges a firing alert. It fails with ErrConflict if
+// the alert is not firing.
+func (s *WeatherService) AcknowledgeAlert(ctx context.Context, id int) error {
+	_, err := s.c.call(ctx, ServiceWeather, http.MethodPost, "/alerts/"+strconv.Itoa(id)+"/acknowledge", nil, nil, nil)
+	return err
+}
+
+// ResolveAlert resolves a firing or acknowledged alert. It fails with
+// ErrConflict if the alert is in neither state.
+func (s *WeatherService) ResolveAlert(ctx context.Context, id int) error {
+	_, err := s.c.call(ctx, ServiceWeather, http.MethodPost, "/alerts/"+strconv.Itoa(id)+"/resolve", nil, nil, nil)
+	return err
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"metagrid/parking/parkingpb"
+)
+
+// parkingSpotsServer implements the gRPC API over the same storage functions
+// as the REST handlers.
+type parkingSpotsServer struct {
+	parkingpb.UnimplementedParkingSpotsServer
+}
+
+var actionsToProto = map[string]parkingpb.Action{
+	"created": parkingpb.Action_ACTION_CREATED,
+	"updated": parkingpb.Action_ACTION_UPDATED,
+	"deleted": parkingpb.Action_ACTION_DELETED,
+}
+
+func parkingSpotToProto(p ParkingSpot) *parkingpb.ParkingSpot {
+	return &parkingpb.ParkingSpot{
+		Id:           int32(p.ID),
+		Location:     p.Location,
+		Availability: p.Availability,
+		CreatedAt:    timestamppb.New(p.CreatedAt),
+	}
+}
+
+func (parkingSpotsServer) CreateParkingSpot(ctx context.Context, req *parkingpb.CreateParkingSpotRequest) (*parkingpb.ParkingSpot, error) {
+	parkingSpot, err := createParkingSpot(ctx, req.GetLocation(), req.GetAvailability())
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to add parking spot")
+	}
+	return parkingSpotToProto(parkingSpot), nil
+}
+
+func (parkingSpotsServer) GetParkingSpot(ctx context.Context, req *parkingpb.GetParkingSpotRequest) (*parkingpb.ParkingSpot, error) {
+	parkingSpot, err := loadParkingSpot(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "parking spot not found", "failed to get parking spot")
+	}
+	return parkingSpotToProto(parkingSpot), nil
+}
+
+func (parkingSpotsServer) UpdateParkingSpot(ctx context.Context, req *parkingpb.UpdateParkingSpotRequest) (*parkingpb.ParkingSpot, error) {
+	parkingSpot, err := setParkingSpotAvailability(ctx, int(req.GetId()), req.GetAvailability())
+	if err != nil {
+		return nil, grpcError(ctx, err, "parking spot not found", "failed to update parking spot")
+	}
+	return parkingSpotToProto(parkingSpot), nil
+}
+
+func (parkingSpotsServer) DeleteParkingSpot(ctx context.Context, req *parkingpb.DeleteParkingSpotRequest) (*emptypb.Empty, error) {
+	if err := removeParkingSpot(ctx, int(req.GetId())); err != nil {
+		return nil, grpcError(ctx, err, "", "failed to delete parking spot")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (parkingSpotsServer) ListParkingSpots(ctx context.Context, req *parkingpb.ListParkingSpotsRequest) (*parkingpb.ListParkingSpotsResponse, error) {
+	p, err := newPage(int(req.GetPageSize()), int(req.GetAfterId()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to query parking spots")
+	}
+	parkingSpots, more, err := loadParkingSpots(ctx, p)
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to query parking spots")
+	}
+	resp := &parkingpb.ListParkingSpotsResponse{}
+	for _, parkingSpot := range parkingSpots {
+		resp.ParkingSpots = append(resp.ParkingSpots, parkingSpotToProto(parkingSpot))
+	}
+	if more {
+		resp.NextAfterId = int32(parkingSpots[len(parkingSpots)-1].ID)
+	}
+	return resp, nil
+}
+
+func (parkingSpotsServer) WatchParkingSpots(req *parkingpb.WatchParkingSpotsRequest, stream parkingpb.ParkingSpots_WatchParkingSpotsServer) error {
+	since := int64(-1)
+	if req.SinceEventId != nil {
+		since = req.GetSinceEventId()
+	}
+	err := followEvents(stream.Context(), since, func(e Event) error {
+		if e.Resource != "parking_spot" {
+			return nil
+		}
+		id, err := strconv.Atoi(e.ResourceID)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", e.ID, err)
+		}
+		msg := &parkingpb.ParkingSpotEvent{
+			EventId:   e.ID,
+			Action:    actionsToProto[e.Action],
+			Id:        int32(id),
+			CreatedAt: timestamppb.New(e.CreatedAt),
+		}
+		if e.Data != nil {
+			var parkingSpot ParkingSpot
+			if err := json.Unmarshal(e.Data, &parkingSpot); err != nil {
+				return fmt.Errorf("event %d: %w", e.ID, err)
+			}
+			msg.ParkingSpot = parkingSpotToProto(parkingSpot)
+		}
+		return stream.Send(msg)
+	}, func() error { return nil })
+	return watchError(err)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	probeTimeout = 2 * time.Second
+	startupRetry = 5 * time.Second
+)
+
+// version is the build version, set with -ldflags "-X main.version=...".
+var version = "dev"
+
+var startedAt = time.Now()
+
+// startup tracks the schema migrations run when the service starts. Until
+// they succeed the instance is not ready and gets no traffic.
+var startup struct {
+	mu       sync.Mutex
+	done     bool
+	attempts int
+	err      error
+}
+
+func startupFailed(err error) {
+	startup.mu.Lock()
+	defer startup.mu.Unlock()
+	startup.attempts++
+	startup.err = err
+}
+
+func startupDone() {
+	startup.mu.Lock()
+	defer startup.mu.Unlock()
+	startup.attempts++
+	startup.done = true
+	startup.err = nil
+}
+
+// Check is the state of one dependency. A check that is not Critical is
+// reported but does not fail the probe.
+type Check struct {
+	Status    string  `json:"status"` // ok, pending or failing
+	Critical  bool    `json:"critical"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Attempts  int     `json:"attempts,omitempty"`
+	// LastHeartbeat is when the registration was last confirmed.
+	LastHeartbeat string `json:"last_heartbeat,omitempty"`
+}
+
+// ProbeResponse is the body of /livez, /readyz and /startupz.
+type ProbeResponse struct {
+	Status        string           `json:"status"` // ok or unavailable
+	ID            string           `json:"id"`
+	Version       string           `json:"version"`
+	UptimeSeconds float64          `json:"uptime_seconds"`
+	Checks        map[string]Check `json:"checks,omitempty"`
+}
+
+func migrationsCheck() Check {
+	startup.mu.Lock()
+	defer startup.mu.Unlock()
+	check := Check{Status: "ok", Critical: true, Attempts: startup.attempts}
+	switch {
+	case startup.done:
+	case startup.err != nil:
+		check.Status = "failing"
+		check.Error = startup.err.Error()
+	default:
+		check.Status = "pending"
+	}
+	return check
+}
+
+func databaseCheck(ctx context.Context) Check {
+	start := time.Now()
+	err := db.PingContext(ctx)
+	return timedCheck(true, start, err)
+}
+
+func timedCheck(critical bool, start time.Time, err error) Check {
+	check := Check{Status: "ok", Critical: critical, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		check.Status = "failing"
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// healthy reports whether every critical check is ok.
+func healthy(checks map[string]Check) bool {
+	for _, check := range checks {
+		if check.Critical && check.Status != "ok" {
+			return false
+		}
+	}
+	return true
+}
+
+func writeProbe(w http.ResponseWriter, checks map[string]Check) {
+	response := ProbeResponse{
+		Status:        "ok",
+		ID:            serviceID,
+		Version:       version,
+		UptimeSeconds: time.Since(startedAt).Seconds(),
+		Checks:        checks,
+	}
+	status := http.StatusOK
+	if !healthy(checks) {
+		response.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// livez reports whether the process is running. It checks no dependencies:
+// restarting the instance would not bring a failed database back.
+func livez(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, nil)
+}
+
+// startupz reports whether the instance has finished starting, which is when
+// its migrations have run.
+func startupz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, map[string]Check{"migrations": migrationsCheck()})
+}
+
+// readiness runs the checks deciding whether the instance can serve
+// requests. The registration heartbeat reports their result to Consul.
+func readiness(ctx context.Context) map[string]Check {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	checks := map[string]Check{
+		"migrations": migrationsCheck(),
+		"database":   databaseCheck(ctx),
+		"registry":   registrationCheck(),
+	}
+	if draining.Load() {
+		checks["draining"] = Check{Status: "failing", Critical: true, Error: "instance is shutting down"}
+	}
+	return checks
+}
+
+// readyz reports whether the instance can serve requests, the same state its
+// Consul heartbeat reports, so an instance still migrating, cut off from
+// Postgres or draining receives no traffic.
+func readyz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, readiness(r.Context()))
+}
@@ -0,0 +1,5 @@
+// Package parkingpb holds the gRPC API of the Parking service, generated from
+// parking.proto.
+package parkingpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative parking.proto
@@ -0,0 +1,695 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: parking.proto
+
+package parkingpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Action int32
+
+const (
+	Action_ACTION_UNSPECIFIED Action = 0
+	Action_ACTION_CREATED     Action = 1
+	Action_ACTION_UPDATED     Action = 2
+	Action_ACTION_DELETED     Action = 3
+)
+
+// Enum value maps for Action.
+var (
+	Action_name = map[int32]string{
+		0: "ACTION_UNSPECIFIED",
+		1: "ACTION_CREATED",
+		2: "ACTION_UPDATED",
+		3: "ACTION_DELETED",
+	}
+	Action_value = map[string]int32{
+		"ACTION_UNSPECIFIED": 0,
+		"ACTION_CREATED":     1,
+		"ACTION_UPDATED":     2,
+		"ACTION_DELETED":     3,
+	}
+)
+
+func (x Action) Enum() *Action {
+	p := new(Action)
+	*p = x
+	return p
+}
+
+func (x Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_parking_proto_enumTypes[0].Descriptor()
+}
+
+func (Action) Type() protoreflect.EnumType {
+	return &file_parking_proto_enumTypes[0]
+}
+
+func (x Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Action.Descriptor instead.
+func (Action) EnumDescriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{0}
+}
+
+type ParkingSpot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Location      string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	Availability  bool                   `protobuf:"varint,3,opt,name=availability,proto3" json:"availability,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParkingSpot) Reset() {
+	*x = ParkingSpot{}
+	mi := &file_parking_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParkingSpot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParkingSpot) ProtoMessage() {}
+
+func (x *ParkingSpot) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParkingSpot.ProtoReflect.Descriptor instead.
+func (*ParkingSpot) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ParkingSpot) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ParkingSpot) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *ParkingSpot) GetAvailability() bool {
+	if x != nil {
+		return x.Availability
+	}
+	return false
+}
+
+func (x *ParkingSpot) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateParkingSpotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      string                 `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Availability  bool                   `protobuf:"varint,2,opt,name=availability,proto3" json:"availability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateParkingSpotRequest) Reset() {
+	*x = CreateParkingSpotRequest{}
+	mi := &file_parking_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateParkingSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateParkingSpotRequest) ProtoMessage() {}
+
+func (x *CreateParkingSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateParkingSpotRequest.ProtoReflect.Descriptor instead.
+func (*CreateParkingSpotRequest) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateParkingSpotRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *CreateParkingSpotRequest) GetAvailability() bool {
+	if x != nil {
+		return x.Availability
+	}
+	return false
+}
+
+type GetParkingSpotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetParkingSpotRequest) Reset() {
+	*x = GetParkingSpotRequest{}
+	mi := &file_parking_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetParkingSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetParkingSpotRequest) ProtoMessage() {}
+
+func (x *GetParkingSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetParkingSpotRequest.ProtoReflect.Descriptor instead.
+func (*GetParkingSpotRequest) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetParkingSpotRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UpdateParkingSpotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Availability  bool                   `protobuf:"varint,2,opt,name=availability,proto3" json:"availability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateParkingSpotRequest) Reset() {
+	*x = UpdateParkingSpotRequest{}
+	mi := &file_parking_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateParkingSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateParkingSpotRequest) ProtoMessage() {}
+
+func (x *UpdateParkingSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateParkingSpotRequest.ProtoReflect.Descriptor instead.
+func (*UpdateParkingSpotRequest) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateParkingSpotRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateParkingSpotRequest) GetAvailability() bool {
+	if x != nil {
+		return x.Availability
+	}
+	return false
+}
+
+type DeleteParkingSpotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteParkingSpotRequest) Reset() {
+	*x = DeleteParkingSpotRequest{}
+	mi := &file_parking_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteParkingSpotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteParkingSpotRequest) ProtoMessage() {}
+
+func (x *DeleteParkingSpotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteParkingSpotRequest.ProtoReflect.Descriptor instead.
+func (*DeleteParkingSpotRequest) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteParkingSpotRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListParkingSpotsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size limits the response to that many parking spots, ordered by id.
+	// Without it every parking spot is returned.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// after_id starts the page after the parking spot with that id.
+	AfterId       int32 `protobuf:"varint,2,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListParkingSpotsRequest) Reset() {
+	*x = ListParkingSpotsRequest{}
+	mi := &file_parking_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListParkingSpotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListParkingSpotsRequest) ProtoMessage() {}
+
+func (x *ListParkingSpotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListParkingSpotsRequest.ProtoReflect.Descriptor instead.
+func (*ListParkingSpotsRequest) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListParkingSpotsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListParkingSpotsRequest) GetAfterId() int32 {
+	if x != nil {
+		return x.AfterId
+	}
+	return 0
+}
+
+type ListParkingSpotsResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ParkingSpots []*ParkingSpot         `protobuf:"bytes,1,rep,name=parking_spots,json=parkingSpots,proto3" json:"parking_spots,omitempty"`
+	// next_after_id is the after_id of the next page, or 0 on the last one.
+	NextAfterId   int32 `protobuf:"varint,2,opt,name=next_after_id,json=nextAfterId,proto3" json:"next_after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListParkingSpotsResponse) Reset() {
+	*x = ListParkingSpotsResponse{}
+	mi := &file_parking_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListParkingSpotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListParkingSpotsResponse) ProtoMessage() {}
+
+func (x *ListParkingSpotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListParkingSpotsResponse.ProtoReflect.Descriptor instead.
+func (*ListParkingSpotsResponse) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListParkingSpotsResponse) GetParkingSpots() []*ParkingSpot {
+	if x != nil {
+		return x.ParkingSpots
+	}
+	return nil
+}
+
+func (x *ListParkingSpotsResponse) GetNextAfterId() int32 {
+	if x != nil {
+		return x.NextAfterId
+	}
+	return 0
+}
+
+type WatchParkingSpotsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When set, the events published after this one are sent first.
+	SinceEventId  *int64 `protobuf:"varint,1,opt,name=since_event_id,json=sinceEventId,proto3,oneof" json:"since_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchParkingSpotsRequest) Reset() {
+	*x = WatchParkingSpotsRequest{}
+	mi := &file_parking_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchParkingSpotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchParkingSpotsRequest) ProtoMessage() {}
+
+func (x *WatchParkingSpotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchParkingSpotsRequest.ProtoReflect.Descriptor instead.
+func (*WatchParkingSpotsRequest) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchParkingSpotsRequest) GetSinceEventId() int64 {
+	if x != nil && x.SinceEventId != nil {
+		return *x.SinceEventId
+	}
+	return 0
+}
+
+type ParkingSpotEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	EventId int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Action  Action                 `protobuf:"varint,2,opt,name=action,proto3,enum=metagrid.parking.v1.Action" json:"action,omitempty"`
+	Id      int32                  `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	// The parking spot after the change; unset when it was deleted.
+	ParkingSpot   *ParkingSpot           `protobuf:"bytes,4,opt,name=parking_spot,json=parkingSpot,proto3" json:"parking_spot,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParkingSpotEvent) Reset() {
+	*x = ParkingSpotEvent{}
+	mi := &file_parking_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParkingSpotEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParkingSpotEvent) ProtoMessage() {}
+
+func (x *ParkingSpotEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_parking_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParkingSpotEvent.ProtoReflect.Descriptor instead.
+func (*ParkingSpotEvent) Descriptor() ([]byte, []int) {
+	return file_parking_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ParkingSpotEvent) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *ParkingSpotEvent) GetAction() Action {
+	if x != nil {
+		return x.Action
+	}
+	return Action_ACTION_UNSPECIFIED
+}
+
+func (x *ParkingSpotEvent) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ParkingSpotEvent) GetParkingSpot() *ParkingSpot {
+	if x != nil {
+		return x.ParkingSpot
+	}
+	return nil
+}
+
+func (x *ParkingSpotEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_parking_proto protoreflect.FileDescriptor
+
+const file_parking_proto_rawDesc = "" +
+	"\n" +
+	"\rparking.proto\x12\x13metagrid.parking.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x98\x01\n" +
+	"\vParkingSpot\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\x12\"\n" +
+	"\favailability\x18\x03 \x01(\bR\favailability\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"Z\n" +
+	"\x18CreateParkingSpotRequest\x12\x1a\n" +
+	"\blocation\x18\x01 \x01(\tR\blocation\x12\"\n" +
+	"\favailability\x18\x02 \x01(\bR\favailability\"'\n" +
+	"\x15GetParkingSpotRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"N\n" +
+	"\x18UpdateParkingSpotRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\"\n" +
+	"\favailability\x18\x02 \x01(\bR\favailability\"*\n" +
+	"\x18DeleteParkingSpotRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"Q\n" +
+	"\x17ListParkingSpotsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x19\n" +
+	"\bafter_id\x18\x02 \x01(\x05R\aafterId\"\x85\x01\n" +
+	"\x18ListParkingSpotsResponse\x12E\n" +
+	"\rparking_spots\x18\x01 \x03(\v2 .metagrid.parking.v1.ParkingSpotR\fparkingSpots\x12\"\n" +
+	"\rnext_after_id\x18\x02 \x01(\x05R\vnextAfterId\"X\n" +
+	"\x18WatchParkingSpotsRequest\x12)\n" +
+	"\x0esince_event_id\x18\x01 \x01(\x03H\x00R\fsinceEventId\x88\x01\x01B\x11\n" +
+	"\x0f_since_event_id\"\xf2\x01\n" +
+	"\x10ParkingSpotEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x123\n" +
+	"\x06action\x18\x02 \x01(\x0e2\x1b.metagrid.parking.v1.ActionR\x06action\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\x05R\x02id\x12C\n" +
+	"\fparking_spot\x18\x04 \x01(\v2 .metagrid.parking.v1.ParkingSpotR\vparkingSpot\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt*\\\n" +
+	"\x06Action\x12\x16\n" +
+	"\x12ACTION_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eACTION_CREATED\x10\x01\x12\x12\n" +
+	"\x0eACTION_UPDATED\x10\x02\x12\x12\n" +
+	"\x0eACTION_DELETED\x10\x032\xf4\x04\n" +
+	"\fParkingSpots\x12d\n" +
+	"\x11CreateParkingSpot\x12-.metagrid.parking.v1.CreateParkingSpotRequest\x1a .metagrid.parking.v1.ParkingSpot\x12^\n" +
+	"\x0eGetParkingSpot\x12*.metagrid.parking.v1.GetParkingSpotRequest\x1a .metagrid.parking.v1.ParkingSpot\x12d\n" +
+	"\x11UpdateParkingSpot\x12-.metagrid.parking.v1.UpdateParkingSpotRequest\x1a .metagrid.parking.v1.ParkingSpot\x12Z\n" +
+	"\x11DeleteParkingSpot\x12-.metagrid.parking.v1.DeleteParkingSpotRequest\x1a\x16.google.protobuf.Empty\x12o\n" +
+	"\x10ListParkingSpots\x12,.metagrid.parking.v1.ListParkingSpotsRequest\x1a-.metagrid.parking.v1.ListParkingSpotsResponse\x12k\n" +
+	"\x11WatchParkingSpots\x12-.metagrid.parking.v1.WatchParkingSpotsRequest\x1a%.metagrid.parking.v1.ParkingSpotEvent0\x01B\x1cZ\x1ametagrid/parking/parkingpbb\x06proto3"
+
+var (
+	file_parking_proto_rawDescOnce sync.Once
+	file_parking_proto_rawDescData []byte
+)
+
+func file_parking_proto_rawDescGZIP() []byte {
+	file_parking_proto_rawDescOnce.Do(func() {
+		file_parking_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_parking_proto_rawDesc), len(file_parking_proto_rawDesc)))
+	})
+	return file_parking_proto_rawDescData
+}
+
+var file_parking_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_parking_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_parking_proto_goTypes = []any{
+	(Action)(0),                      // 0: metagrid.parking.v1.Action
+	(*ParkingSpot)(nil),              // 1: metagrid.parking.v1.ParkingSpot
+	(*CreateParkingSpotRequest)(nil), // 2: metagrid.parking.v1.CreateParkingSpotRequest
+	(*GetParkingSpotRequest)(nil),    // 3: metagrid.parking.v1.GetParkingSpotRequest
+	(*UpdateParkingSpotRequest)(nil), // 4: metagrid.parking.v1.UpdateParkingSpotRequest
+	(*DeleteParkingSpotRequest)(nil), // 5: metagrid.parking.v1.DeleteParkingSpotRequest
+	(*ListParkingSpotsRequest)(nil),  // 6: metagrid.parking.v1.ListParkingSpotsRequest
+	(*ListParkingSpotsResponse)(nil), // 7: metagrid.parking.v1.ListParkingSpotsResponse
+	(*WatchParkingSpotsRequest)(nil), // 8: metagrid.parking.v1.WatchParkingSpotsRequest
+	(*ParkingSpotEvent)(nil),         // 9: metagrid.parking.v1.ParkingSpotEvent
+	(*timestamppb.Timestamp)(nil),    // 10: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),            // 11: google.protobuf.Empty
+}
+var file_parking_proto_depIdxs = []int32{
+	10, // 0: metagrid.parking.v1.ParkingSpot.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 1: metagrid.parking.v1.ListParkingSpotsResponse.parking_spots:type_name -> metagrid.parking.v1.ParkingSpot
+	0,  // 2: metagrid.parking.v1.ParkingSpotEvent.action:type_name -> metagrid.parking.v1.Action
+	1,  // 3: metagrid.parking.v1.ParkingSpotEvent.parking_spot:type_name -> metagrid.parking.v1.ParkingSpot
+	10, // 4: metagrid.parking.v1.ParkingSpotEvent.created_at:type_name -> google.protobuf.Timestamp
+	2,  // 5: metagrid.parking.v1.ParkingSpots.CreateParkingSpot:input_type -> metagrid.parking.v1.CreateParkingSpotRequest
+	3,  // 6: metagrid.parking.v1.ParkingSpots.GetParkingSpot:input_type -> metagrid.parking.v1.GetParkingSpotRequest
+	4,  // 7: metagrid.parking.v1.ParkingSpots.UpdateParkingSpot:input_type -> metagrid.parking.v1.UpdateParkingSpotRequest
+	5,  // 8: metagrid.parking.v1.ParkingSpots.DeleteParkingSpot:input_type -> metagrid.parking.v1.DeleteParkingSpotRequest
+	6,  // 9: metagrid.parking.v1.ParkingSpots.ListParkingSpots:input_type -> metagrid.parking.v1.ListParkingSpotsRequest
+	8,  // 10: metagrid.parking.v1.ParkingSpots.WatchParkingSpots:input_type -> metagrid.parking.v1.WatchParkingSpotsRequest
+	1,  // 11: metagrid.parking.v1.ParkingSpots.CreateParkingSpot:output_type -> metagrid.parking.v1.ParkingSpot
+	1,  // 12: metagrid.parking.v1.ParkingSpots.GetParkingSpot:output_type -> metagrid.parking.v1.ParkingSpot
+	1,  // 13: metagrid.parking.v1.ParkingSpots.UpdateParkingSpot:output_type -> metagrid.parking.v1.ParkingSpot
+	11, // 14: metagrid.parking.v1.ParkingSpots.DeleteParkingSpot:output_type -> google.protobuf.Empty
+	7,  // 15: metagrid.parking.v1.ParkingSpots.ListParkingSpots:output_type -> metagrid.parking.v1.ListParkingSpotsResponse
+	9,  // 16: metagrid.parking.v1.ParkingSpots.WatchParkingSpots:output_type -> metagrid.parking.v1.ParkingSpotEvent
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_parking_proto_init() }
+func file_parking_proto_init() {
+	if File_parking_proto != nil {
+		return
+	}
+	file_parking_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_parking_proto_rawDesc), len(file_parking_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_parking_proto_goTypes,
+		DependencyIndexes: file_parking_proto_depIdxs,
+		EnumInfos:         file_parking_proto_enumTypes,
+		MessageInfos:      file_parking_proto_msgTypes,
+	}.Build()
+	File_parking_proto = out.File
+	file_parking_proto_goTypes = nil
+	file_parking_proto_depIdxs = nil
+}
@@ -0,0 +1,336 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: parking.proto
+
+package parkingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ParkingSpots_CreateParkingSpot_FullMethodName = "/metagrid.parking.v1.ParkingSpots/CreateParkingSpot"
+	ParkingSpots_GetParkingSpot_FullMethodName    = "/metagrid.parking.v1.ParkingSpots/GetParkingSpot"
+	ParkingSpots_UpdateParkingSpot_FullMethodName = "/metagrid.parking.v1.ParkingSpots/UpdateParkingSpot"
+	ParkingSpots_DeleteParkingSpot_FullMethodName = "/metagrid.parking.v1.ParkingSpots/DeleteParkingSpot"
+	ParkingSpots_ListParkingSpots_FullMethodName  = "/metagrid.parking.v1.ParkingSpots/ListParkingSpots"
+	ParkingSpots_WatchParkingSpots_FullMethodName = "/metagrid.parking.v1.ParkingSpots/WatchParkingSpots"
+)
+
+// ParkingSpotsClient is the client API for ParkingSpots service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ParkingSpots is the gRPC API of the Parking service. It serves the same
+// parking spots as the REST API, with the same validation, and streams their
+// changes from the same event log as /events.
+type ParkingSpotsClient interface {
+	CreateParkingSpot(ctx context.Context, in *CreateParkingSpotRequest, opts ...grpc.CallOption) (*ParkingSpot, error)
+	GetParkingSpot(ctx context.Context, in *GetParkingSpotRequest, opts ...grpc.CallOption) (*ParkingSpot, error)
+	UpdateParkingSpot(ctx context.Context, in *UpdateParkingSpotRequest, opts ...grpc.CallOption) (*ParkingSpot, error)
+	// DeleteParkingSpot succeeds for a parking spot that does not exist, as
+	// DELETE does.
+	DeleteParkingSpot(ctx context.Context, in *DeleteParkingSpotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListParkingSpots(ctx context.Context, in *ListParkingSpotsRequest, opts ...grpc.CallOption) (*ListParkingSpotsResponse, error)
+	// WatchParkingSpots streams every change to a parking spot until the
+	// client cancels. It ends with UNAVAILABLE when the instance shuts down or
+	// the client falls too far behind; the client then resumes from the last
+	// event it received with since_event_id.
+	WatchParkingSpots(ctx context.Context, in *WatchParkingSpotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ParkingSpotEvent], error)
+}
+
+type parkingSpotsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewParkingSpotsClient(cc grpc.ClientConnInterface) ParkingSpotsClient {
+	return &parkingSpotsClient{cc}
+}
+
+func (c *parkingSpotsClient) CreateParkingSpot(ctx context.Context, in *CreateParkingSpotRequest, opts ...grpc.CallOption) (*ParkingSpot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParkingSpot)
+	err := c.cc.Invoke(ctx, ParkingSpots_CreateParkingSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingSpotsClient) GetParkingSpot(ctx context.Context, in *GetParkingSpotRequest, opts ...grpc.CallOption) (*ParkingSpot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParkingSpot)
+	err := c.cc.Invoke(ctx, ParkingSpots_GetParkingSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingSpotsClient) UpdateParkingSpot(ctx context.Context, in *UpdateParkingSpotRequest, opts ...grpc.CallOption) (*ParkingSpot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParkingSpot)
+	err := c.cc.Invoke(ctx, ParkingSpots_UpdateParkingSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingSpotsClient) DeleteParkingSpot(ctx context.Context, in *DeleteParkingSpotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ParkingSpots_DeleteParkingSpot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingSpotsClient) ListParkingSpots(ctx context.Context, in *ListParkingSpotsRequest, opts ...grpc.CallOption) (*ListParkingSpotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListParkingSpotsResponse)
+	err := c.cc.Invoke(ctx, ParkingSpots_ListParkingSpots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parkingSpotsClient) WatchParkingSpots(ctx context.Context, in *WatchParkingSpotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ParkingSpotEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ParkingSpots_ServiceDesc.Streams[0], ParkingSpots_WatchParkingSpots_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchParkingSpotsRequest, ParkingSpotEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ParkingSpots_WatchParkingSpotsClient = grpc.ServerStreamingClient[ParkingSpotEvent]
+
+// ParkingSpotsServer is the server API for ParkingSpots service.
+// All implementations must embed UnimplementedParkingSpotsServer
+// for forward compatibility.
+//
+// ParkingSpots is the gRPC API of the Parking service. It serves the same
+// parking spots as the REST API, with the same validation, and streams their
+// changes from the same event log as /events.
+type ParkingSpotsServer interface {
+	CreateParkingSpot(context.Context, *CreateParkingSpotRequest) (*ParkingSpot, error)
+	GetParkingSpot(context.Context, *GetParkingSpotRequest) (*ParkingSpot, error)
+	UpdateParkingSpot(context.Context, *UpdateParkingSpotRequest) (*ParkingSpot, error)
+	// DeleteParkingSpot succeeds for a parking spot that does not exist, as
+	// DELETE does.
+	DeleteParkingSpot(context.Context, *DeleteParkingSpotRequest) (*emptypb.Empty, error)
+	ListParkingSpots(context.Context, *ListParkingSpotsRequest) (*ListParkingSpotsResponse, error)
+	// WatchParkingSpots streams every change to a parking spot until the
+	// client cancels. It ends with UNAVAILABLE when the instance shuts down or
+	// the client falls too far behind; the client then resumes from the last
+	// event it received with since_event_id.
+	WatchParkingSpots(*WatchParkingSpotsRequest, grpc.ServerStreamingServer[ParkingSpotEvent]) error
+	mustEmbedUnimplementedParkingSpotsServer()
+}
+
+// UnimplementedParkingSpotsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedParkingSpotsServer struct{}
+
+func (UnimplementedParkingSpotsServer) CreateParkingSpot(context.Context, *CreateParkingSpotRequest) (*ParkingSpot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateParkingSpot not implemented")
+}
+func (UnimplementedParkingSpotsServer) GetParkingSpot(context.Context, *GetParkingSpotRequest) (*ParkingSpot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetParkingSpot not implemented")
+}
+func (UnimplementedParkingSpotsServer) UpdateParkingSpot(context.Context, *UpdateParkingSpotRequest) (*ParkingSpot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateParkingSpot not implemented")
+}
+func (UnimplementedParkingSpotsServer) DeleteParkingSpot(context.Context, *DeleteParkingSpotRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteParkingSpot not implemented")
+}
+func (UnimplementedParkingSpotsServer) ListParkingSpots(context.Context, *ListParkingSpotsRequest) (*ListParkingSpotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListParkingSpots not implemented")
+}
+func (UnimplementedParkingSpotsServer) WatchParkingSpots(*WatchParkingSpotsRequest, grpc.ServerStreamingServer[ParkingSpotEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchParkingSpots not implemented")
+}
+func (UnimplementedParkingSpotsServer) mustEmbedUnimplementedParkingSpotsServer() {}
+func (UnimplementedParkingSpotsServer) testEmbeddedByValue()                      {}
+
+// UnsafeParkingSpotsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ParkingSpotsServer will
+// result in compilation errors.
+type UnsafeParkingSpotsServer interface {
+	mustEmbedUnimplementedParkingSpotsServer()
+}
+
+func RegisterParkingSpotsServer(s grpc.ServiceRegistrar, srv ParkingSpotsServer) {
+	// If the following call pancis, it indicates UnimplementedParkingSpotsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ParkingSpots_ServiceDesc, srv)
+}
+
+func _ParkingSpots_CreateParkingSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateParkingSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingSpotsServer).CreateParkingSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingSpots_CreateParkingSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingSpotsServer).CreateParkingSpot(ctx, req.(*CreateParkingSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingSpots_GetParkingSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetParkingSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingSpotsServer).GetParkingSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingSpots_GetParkingSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingSpotsServer).GetParkingSpot(ctx, req.(*GetParkingSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingSpots_UpdateParkingSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateParkingSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingSpotsServer).UpdateParkingSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingSpots_UpdateParkingSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingSpotsServer).UpdateParkingSpot(ctx, req.(*UpdateParkingSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingSpots_DeleteParkingSpot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteParkingSpotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingSpotsServer).DeleteParkingSpot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingSpots_DeleteParkingSpot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingSpotsServer).DeleteParkingSpot(ctx, req.(*DeleteParkingSpotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingSpots_ListParkingSpots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListParkingSpotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParkingSpotsServer).ListParkingSpots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParkingSpots_ListParkingSpots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParkingSpotsServer).ListParkingSpots(ctx, req.(*ListParkingSpotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParkingSpots_WatchParkingSpots_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchParkingSpotsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ParkingSpotsServer).WatchParkingSpots(m, &grpc.GenericServerStream[WatchParkingSpotsRequest, ParkingSpotEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ParkingSpots_WatchParkingSpotsServer = grpc.ServerStreamingServer[ParkingSpotEvent]
+
+// ParkingSpots_ServiceDesc is the grpc.ServiceDesc for ParkingSpots service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ParkingSpots_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "metagrid.parking.v1.ParkingSpots",
+	HandlerType: (*ParkingSpotsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateParkingSpot",
+			Handler:    _ParkingSpots_CreateParkingSpot_Handler,
+		},
+		{
+			MethodName: "GetParkingSpot",
+			Handler:    _ParkingSpots_GetParkingSpot_Handler,
+		},
+		{
+			MethodName: "UpdateParkingSpot",
+			Handler:    _ParkingSpots_UpdateParkingSpot_Handler,
+		},
+		{
+			MethodName: "DeleteParkingSpot",
+			Handler:    _ParkingSpots_DeleteParkingSpot_Handler,
+		},
+		{
+			MethodName: "ListParkingSpots",
+			Handler:    _ParkingSpots_ListParkingSpots_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchParkingSpots",
+			Handler:       _ParkingSpots_WatchParkingSpots_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "parking.proto",
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// The storage functions below are shared by the REST handlers and the gRPC
+// server, so both validate input the same way and publish the same events.
+
+const parkingSpotColumns = `id, location, availability, created_at`
+
+func scanParkingSpot(row interface{ Scan(...any) error }) (ParkingSpot, error) {
+	var parkingSpot ParkingSpot
+	err := row.Scan(&parkingSpot.ID, &parkingSpot.Location, &parkingSpot.Availability, &parkingSpot.CreatedAt)
+	return parkingSpot, err
+}
+
+// createParkingSpot stores a new parking spot and publishes its created event.
+func createParkingSpot(ctx context.Context, location string, availability bool) (ParkingSpot, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return ParkingSpot{}, err
+	}
+	defer tx.Rollback()
+
+	parkingSpot, err := scanParkingSpot(tx.QueryRowContext(ctx,
+		`INSERT INTO parking (location, availability) VALUES ($1, $2) RETURNING `+parkingSpotColumns,
+		location, availability,
+	))
+	if err != nil {
+		return parkingSpot, err
+	}
+	if err := publishEvent(tx, "parking_spot", "created", strconv.Itoa(parkingSpot.ID), parkingSpot); err != nil {
+		return parkingSpot, err
+	}
+	return parkingSpot, tx.Commit()
+}
+
+// loadParkingSpot returns errNotFound for a parking spot that does not exist.
+func loadParkingSpot(ctx context.Context, id int) (ParkingSpot, error) {
+	parkingSpot, err := scanParkingSpot(db.QueryRowContext(ctx, `SELECT `+parkingSpotColumns+` FROM parking WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return parkingSpot, errNotFound
+	}
+	return parkingSpot, err
+}
+
+// setParkingSpotAvailability changes the availability of a parking spot and
+// publishes its updated event.
+func setParkingSpotAvailability(ctx context.Context, id int, availability bool) (ParkingSpot, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return ParkingSpot{}, err
+	}
+	defer tx.Rollback()
+
+	parkingSpot, err := scanParkingSpot(tx.QueryRowContext(ctx,
+		`UPDATE parking SET availability = $1 WHERE id = $2 RETURNING `+parkingSpotColumns,
+		availability, id,
+	))
+	if err == sql.ErrNoRows {
+		return parkingSpot, errNotFound
+	}
+	if err != nil {
+		return parkingSpot, err
+	}
+	if err := publishEvent(tx, "parking_spot", "updated", strconv.Itoa(parkingSpot.ID), parkingSpot); err != nil {
+		return parkingSpot, err
+	}
+	return parkingSpot, tx.Commit()
+}
+
+// removeParkingSpot deletes a parking spot and publishes its deleted event.
+// Deleting one that does not exist is not an error.
+func removeParkingSpot(ctx context.Context, id int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deleted int
+	err = tx.QueryRowContext(ctx, `DELETE FROM parking WHERE id = $1 RETURNING id`, id).Scan(&deleted)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := publishEvent(tx, "parking_spot", "deleted", strconv.Itoa(deleted), nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loadParkingSpots returns the parking spots in page p and whether more
+// follow it.
+func loadParkingSpots(ctx context.Context, p page) ([]ParkingSpot, bool, error) {
+	clause, args := p.clause(1)
+	rows, err := db.QueryContext(ctx, `SELECT `+parkingSpotColumns+` FROM parking`+clause, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var parkingSpots []ParkingSpot
+	for rows.Next() {
+		parkingSpot, err := scanParkingSpot(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		parkingSpots = append(parkingSpots, parkingSpot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	if p.more(len(parkingSpots)) {
+		return parkingSpots[:p.limit], true, nil
+	}
+	return parkingSpots, false, nil
+}
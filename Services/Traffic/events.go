@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	eventsChannel   = "traffic_events"
+	eventRetention  = 7 * 24 * time.Hour
+	eventKeepAlive  = 15 * time.Second
+	subscriberQueue = 64
+	// Postgres rejects NOTIFY payloads from 8000 bytes; larger events are
+	// announced by ID and read back from the table.
+	maxNotifyPayload = 7000
+)
+
+const eventsTable = `CREATE TABLE IF NOT EXISTS traffic_events (
+	id BIGSERIAL PRIMARY KEY,
+	resource TEXT NOT NULL,
+	action TEXT NOT NULL,
+	resource_id TEXT NOT NULL,
+	data JSONB,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// Event is a change to a resource, as stored in traffic_events and sent on
+// the /events stream.
+type Event struct {
+	ID         int64           `json:"id"`
+	Resource   string          `json:"resource"`
+	Action     string          `json:"action"` // created, updated or deleted
+	ResourceID string          `json:"resource_id"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// publishEvent records an event in tx, queues it for matching webhooks and
+// notifies every instance of it. Postgres delivers the notification only once
+// tx commits. Callers publish as the last step before committing, as events
+// are written one transaction at a time.
+func publishEvent(tx *sql.Tx, resource, action, resourceID string, data any) error {
+	e := Event{Resource: resource, Action: action, ResourceID: resourceID}
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		e.Data = encoded
+	}
+
+	// Ids are assigned on insert but become visible on commit, so concurrent
+	// transactions could commit their events out of order, and a reader
+	// resuming after the later one would never see the earlier. Holding this
+	// lock from the insert until commit makes events commit in id order, so a
+	// single id is a complete cursor for Last-Event-ID and since_event_id.
+	// The price is that writes from every replica queue here between their
+	// first event and commit, which stays short as long as callers publish
+	// last.
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, eventsChannel); err != nil {
+		return err
+	}
+	err := tx.QueryRow(
+		`INSERT INTO traffic_events (resource, action, resource_id, data) VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		e.Resource, e.Action, e.ResourceID, nullableJSON(e.Data),
+	).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := enqueueWebhooks(tx, e); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		payload = []byte(strconv.FormatInt(e.ID, 10))
+	}
+	_, err = tx.Exec(`SELECT pg_notify($1, $2)`, eventsChannel, string(payload))
+	return err
+}
+
+func nullableJSON(data json.RawMessage) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+func scanEvent(row interface{ Scan(...any) error }) (Event, error) {
+	var e Event
+	var data []byte
+	err := row.Scan(&e.ID, &e.Resource, &e.Action, &e.ResourceID, &data, &e.CreatedAt)
+	if data != nil {
+		e.Data = data
+	}
+	return e, err
+}
+
+// eventBroker fans events received from Postgres out to the /events streams
+// connected to this instance.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	lastID      int64
+	closed      bool
+}
+
+var events = &eventBroker{subscribers: map[chan Event]struct{}{}}
+
+func (b *eventBroker) subscribe() chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, subscriberQueue)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// broadcast sends e to every subscriber. A subscriber that has fallen a whole
+// queue behind is disconnected; its client resumes with Last-Event-ID.
+func (b *eventBroker) broadcast(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID = max(b.lastID, e.ID)
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// close ends every stream so the server can shut down.
+func (b *eventBroker) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// listenForEvents relays notifications on eventsChannel to the broker and
+// applies log level changes sent on logLevelChannel. After the listener
+// reconnects it replays what was published in the meantime, and it prunes
+// events older than eventRetention once an hour.
+func listenForEvents() {
+	listener := pq.NewListener(databaseURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("Event listener error", "error", err)
+		}
+	})
+	for _, channel := range []string{eventsChannel, logLevelChannel} {
+		if err := listener.Listen(channel); err != nil {
+			slog.Error("Failed to listen", "channel", channel, "error", err)
+			return
+		}
+	}
+
+	prune := time.NewTicker(time.Hour)
+	defer prune.Stop()
+	for {
+		select {
+		case n := <-listener.Notify:
+			if n == nil {
+				// Reconnected; notifications sent while away are lost.
+				events.catchUp()
+				continue
+			}
+			if n.Channel == logLevelChannel {
+				applyLogLevel(n.Extra)
+				continue
+			}
+			if e, ok := decodeNotification(n.Extra); ok {
+				events.broadcast(e)
+			}
+		case <-prune.C:
+			if _, err := db.Exec(`DELETE FROM traffic_events WHERE created_at < $1`, time.Now().UTC().Add(-eventRetention)); err != nil {
+				slog.Error("Failed to prune events", "error", err)
+			}
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
+
+func decodeNotification(payload string) (Event, bool) {
+	var e Event
+	if !strings.HasPrefix(payload, "{") {
+		var err error
+		e, err = scanEvent(db.QueryRow(`SELECT id, resource, action, resource_id, data, created_at FROM traffic_events WHERE id = $1`, payload))
+		if err != nil {
+			slog.Error("Failed to load event", "event_id", payload, "error", err)
+			return e, false
+		}
+		return e, true
+	}
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		slog.Error("Failed to decode event", "error", err)
+		return e, false
+	}
+	return e, true
+}
+
+func (b *eventBroker) catchUp() {
+	b.mu.Lock()
+	lastID := b.lastID
+	b.mu.Unlock()
+
+	rows, err := db.Query(`SELECT id, resource, action, resource_id, data, created_at FROM traffic_events WHERE id > $1 ORDER BY id`, lastID)
+	if err != nil {
+		slog.Error("Failed to replay events", "error", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			slog.Error("Failed to replay events", "error", err)
+			return
+		}
+		b.broadcast(e)
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s.%s\ndata: %s\n\n", e.ID, e.Resource, e.Action, data)
+	return err
+}
+
+// errEventsClosed ends a stream whose subscription the broker closed, because
+// the instance is shutting down or the stream fell a whole queue behind. The
+// client resumes from the last event it received.
+var errEventsClosed = errors.New("event stream closed")
+
+// followEvents sends the events published after since, unless since is
+// negative, then every event published until ctx is done, send fails or the
+// broker closes the subscription, which returns errEventsClosed. idle is
+// called when no event has been sent for eventKeepAlive. Both the SSE stream
+// and the gRPC Watch calls are served by it.
+func followEvents(ctx context.Context, since int64, send func(Event) error, idle func() error) error {
+	// Subscribe before replaying so nothing published in between is missed.
+	ch := events.subscribe()
+	defer events.unsubscribe(ch)
+
+	replayed := map[int64]bool{}
+	if since >= 0 {
+		rows, err := db.QueryContext(ctx, `SELECT id, resource, action, resource_id, data, created_at FROM traffic_events WHERE id > $1 ORDER BY id`, since)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to replay events", "error", err)
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			e, err := scanEvent(rows)
+			if err != nil {
+				return err
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+			replayed[e.ID] = true
+		}
+		rows.Close()
+	}
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return errEventsClosed
+			}
+			if replayed[e.ID] {
+				continue
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+			keepAlive.Reset(eventKeepAlive)
+		case <-keepAlive.C:
+			if err := idle(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// streamEvents serves GET /events as Server-Sent Events. A client that sends
+// Last-Event-ID, or last_event_id in the query for clients that cannot set
+// headers, first receives the events it missed.
+func streamEvents(w http.ResponseWriter, r *http.Request) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var since int64 = -1
+	if lastEventID != "" {
+		var err error
+		if since, err = strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	followEvents(r.Context(), since, func(e Event) error {
+		if err := writeEvent(w, e); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}, func() error {
+		if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadata carries the request ID in gRPC metadata, as the
+// X-Request-Id header does over HTTP.
+const requestIDMetadata = "x-request-id"
+
+// grpcHealth serves grpc.health.v1 for the server and each of its services.
+// Consul checks the gRPC endpoint through it.
+var grpcHealth = health.NewServer()
+
+// quietRPCs are called by Consul; their successful calls are only logged at
+// debug level.
+var quietRPCs = map[string]bool{healthpb.Health_Check_FullMethodName: true}
+
+// newGRPCServer returns a gRPC server with the health and reflection services,
+// tracing, logging and metrics. register adds the service's API.
+func newGRPCServer(register func(*grpc.Server)) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(observeUnaryRPC),
+		grpc.ChainStreamInterceptor(observeStreamRPC),
+	)
+	healthpb.RegisterHealthServer(server, grpcHealth)
+	reflection.Register(server)
+	register(server)
+	return server
+}
+
+// reportGRPCHealth keeps the health of the server and its services in step
+// with readiness, so Consul stops routing to an instance still migrating or
+// cut off from Postgres. drain marks them NOT_SERVING for good.
+func reportGRPCHealth(server *grpc.Server) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if healthy(readiness(context.Background())) {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		grpcHealth.SetServingStatus("", status)
+		for name := range server.GetServiceInfo() {
+			if name != healthpb.Health_ServiceDesc.ServiceName {
+				grpcHealth.SetServingStatus(name, status)
+			}
+		}
+		<-ticker.C
+	}
+}
+
+// withRequestID adds the request ID sent by the client, or a new one, to ctx
+// so it is logged with every line, and returns it to the client.
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(requestIDMetadata)) > 0 {
+		id = md.Get(requestIDMetadata)[0]
+	}
+	if id == "" {
+		id = fmt.Sprintf("%s-%06d", serviceID, middleware.NextRequestID())
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	return context.WithValue(ctx, middleware.RequestIDKey, id)
+}
+
+func observeUnaryRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx = withRequestID(ctx)
+	resp, err := handler(ctx, req)
+	observeRPC(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func observeStreamRPC(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := withRequestID(ss.Context())
+	err := handler(srv, contextStream{ServerStream: ss, ctx: ctx})
+	observeRPC(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// contextStream replaces the context of a stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// observeRPC logs a line for every call once it has ended, at the same levels
+// as logRequests, and records its count and latency.
+func observeRPC(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	elapsed := time.Since(start)
+	grpcRequests.WithLabelValues(method, code.String()).Inc()
+	grpcDuration.WithLabelValues(method, code.String()).Observe(elapsed.Seconds())
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+		if quietRPCs[method] {
+			level = slog.LevelDebug
+		}
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String("remote_addr", p.Addr.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	slog.LogAttrs(ctx, level, "rpc", attrs...)
+}
+
+// grpcError converts an error from the storage functions to the status sent
+// to the client: invalid input and conflicts are described, notFound is the
+// message for errNotFound and failure the message for anything unexpected,
+// whose details are only logged.
+func grpcError(ctx context.Context, err error, notFound, failure string) error {
+	var invalid invalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, errNotFound):
+		return status.Error(codes.NotFound, notFound)
+	case errors.Is(err, errConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	slog.ErrorContext(ctx, failure, "error", err)
+	return status.Error(codes.Internal, failure)
+}
+
+// watchError converts the error that ended followEvents for a Watch call.
+func watchError(err error) error {
+	if errors.Is(err, errEventsClosed) {
+		return status.Error(codes.Unavailable, "event stream closed; resume with since_event_id")
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, "failed to stream events")
+}
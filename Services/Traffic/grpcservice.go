@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"metagrid/trafficLights/trafficpb"
+)
+
+// trafficLightsServer implements the gRPC API over the same storage
+// functions as the REST handlers.
+type trafficLightsServer struct {
+	trafficpb.UnimplementedTrafficLightsServer
+}
+
+var (
+	colorsToProto = map[string]trafficpb.Color{
+		"red":    trafficpb.Color_COLOR_RED,
+		"yellow": trafficpb.Color_COLOR_YELLOW,
+		"green":  trafficpb.Color_COLOR_GREEN,
+	}
+	actionsToProto = map[string]trafficpb.Action{
+		"created": trafficpb.Action_ACTION_CREATED,
+		"updated": trafficpb.Action_ACTION_UPDATED,
+		"deleted": trafficpb.Action_ACTION_DELETED,
+	}
+)
+
+func trafficLightToProto(t TrafficLight) *trafficpb.TrafficLight {
+	return &trafficpb.TrafficLight{Id: int32(t.ID), Location: t.Location, Color: colorsToProto[t.Color]}
+}
+
+// colorFromProto returns the color as the storage functions name it. An
+// unspecified color is empty, which setTrafficLightColor rejects.
+func colorFromProto(c trafficpb.Color) string {
+	for name, value := range colorsToProto {
+		if value == c {
+			return name
+		}
+	}
+	return ""
+}
+
+func (trafficLightsServer) CreateTrafficLight(ctx context.Context, req *trafficpb.CreateTrafficLightRequest) (*trafficpb.TrafficLight, error) {
+	trafficLight, err := createTrafficLight(ctx, req.GetLocation(), colorFromProto(req.GetColor()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to add traffic light")
+	}
+	return trafficLightToProto(trafficLight), nil
+}
+
+func (trafficLightsServer) GetTrafficLight(ctx context.Context, req *trafficpb.GetTrafficLightRequest) (*trafficpb.TrafficLight, error) {
+	trafficLight, err := loadTrafficLight(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "traffic light not found", "failed to get traffic light")
+	}
+	return trafficLightToProto(trafficLight), nil
+}
+
+func (trafficLightsServer) UpdateTrafficLight(ctx context.Context, req *trafficpb.UpdateTrafficLightRequest) (*trafficpb.TrafficLight, error) {
+	trafficLight, err := setTrafficLightColor(ctx, int(req.GetId()), colorFromProto(req.GetColor()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "traffic light not found", "failed to update traffic light")
+	}
+	return trafficLightToProto(trafficLight), nil
+}
+
+func (trafficLightsServer) DeleteTrafficLight(ctx context.Context, req *trafficpb.DeleteTrafficLightRequest) (*emptypb.Empty, error) {
+	if err := removeTrafficLight(ctx, int(req.GetId())); err != nil {
+		return nil, grpcError(ctx, err, "", "failed to delete traffic light")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (trafficLightsServer) ListTrafficLights(ctx context.Context, req *trafficpb.ListTrafficLightsRequest) (*trafficpb.ListTrafficLightsResponse, error) {
+	p, err := newPage(int(req.GetPageSize()), int(req.GetAfterId()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to query traffic lights")
+	}
+	trafficLights, more, err := loadTrafficLights(ctx, p)
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to query traffic lights")
+	}
+	resp := &trafficpb.ListTrafficLightsResponse{}
+	for _, trafficLight := range trafficLights {
+		resp.TrafficLights = append(resp.TrafficLights, trafficLightToProto(trafficLight))
+	}
+	if more {
+		resp.NextAfterId = int32(trafficLights[len(trafficLights)-1].ID)
+	}
+	return resp, nil
+}
+
+func (trafficLightsServer) WatchTrafficLights(req *trafficpb.WatchTrafficLightsRequest, stream trafficpb.TrafficLights_WatchTrafficLightsServer) error {
+	since := int64(-1)
+	if req.SinceEventId != nil {
+		since = req.GetSinceEventId()
+	}
+	err := followEvents(stream.Context(), since, func(e Event) error {
+		if e.Resource != "traffic_light" {
+			return nil
+		}
+		id, err := strconv.Atoi(e.ResourceID)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", e.ID, err)
+		}
+		msg := &trafficpb.TrafficLightEvent{
+			EventId:   e.ID,
+			Action:    actionsToProto[e.Action],
+			Id:        int32(id),
+			CreatedAt: timestamppb.New(e.CreatedAt),
+		}
+		if e.Data != nil {
+			var trafficLight TrafficLight
+			if err := json.Unmarshal(e.Data, &trafficLight); err != nil {
+				return fmt.Errorf("event %d: %w", e.ID, err)
+			}
+			msg.TrafficLight = trafficLightToProto(trafficLight)
+		}
+		return stream.Send(msg)
+	}, func() error { return nil })
+	return watchError(err)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// openAPIDocument is the OpenAPI 3 description of the service, served at
+// /openapi.json. "main check-openapi", which the Docker build runs, fails
+// when it and the router or the response types have drifted apart.
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// docsPage renders openapi.json with Swagger UI.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>API documentation</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+    <script>
+        SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+    </script>
+</body>
+</html>
+`
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDocument)
+}
+
+func serveDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
+
+// openAPISpec is the part of the document the checks read.
+type openAPISpec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// schema is the subset of an OpenAPI schema object the documents use.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	// AllOf holds a single $ref where a reference has to be nullable.
+	AllOf []*schema `json:"allOf"`
+}
+
+var operationMethods = []string{"get", "put", "post", "delete", "patch", "head", "options", "trace"}
+
+// checkOpenAPI implements "main check-openapi". It reports every route the
+// document does not describe, every operation it describes that is not
+// routed, and every response type in openAPITypes that differs from its
+// schema.
+func checkOpenAPI() int {
+	var spec openAPISpec
+	if err := json.Unmarshal(openAPIDocument, &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "openapi.json: %v\n", err)
+		return 1
+	}
+	problems, err := checkRoutes(&spec, newRouter())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to walk the routes: %v\n", err)
+		return 1
+	}
+	names := make([]string, 0, len(openAPITypes))
+	for name := range openAPITypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s, ok := spec.Components.Schemas[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("schema %s is not in the document", name))
+			continue
+		}
+		problems = append(problems, checkSchema(&spec, name, reflect.TypeOf(openAPITypes[name]), s)...)
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "openapi.json does not match the service: %d problems\n", len(problems))
+		return 1
+	}
+	fmt.Println("openapi.json matches the routes and response types")
+	return 0
+}
+
+// checkRoutes compares the operations in the document with the routes of r.
+func checkRoutes(spec *openAPISpec, r chi.Routes) ([]string, error) {
+	documented := make(map[string]bool)
+	for path, item := range spec.Paths {
+		for method := range item {
+			if slices.Contains(operationMethods, method) {
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+	routed := make(map[string]bool)
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routed[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for route := range routed {
+		if !documented[route] {
+			problems = append(problems, fmt.Sprintf("route %s is not documented", route))
+		}
+	}
+	for operation := range documented {
+		if !routed[operation] {
+			problems = append(problems, fmt.Sprintf("operation %s is not routed", operation))
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+var (
+	timeType = reflect.TypeFor[time.Time]()
+	rawType  = reflect.TypeFor[json.RawMessage]()
+)
+
+// checkSchema compares the JSON encoding of t with s. Every field must be a
+// property of a matching type, required unless it is omitted when empty, and
+// every property must be a field.
+func checkSchema(spec *openAPISpec, at string, t reflect.Type, s *schema) []string {
+	if len(s.AllOf) == 1 {
+		s = s.AllOf[0]
+	}
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		ref, ok := spec.Components.Schemas[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", at, s.Ref)}
+		}
+		s = ref
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	mismatch := func(want string) []string {
+		return []string{fmt.Sprintf("%s: %s is encoded as %s but documented as %s", at, t, want, strings.TrimSpace(s.Type+" "+s.Format))}
+	}
+
+	switch {
+	case t == rawType:
+		return nil
+	case t == timeType:
+		if s.Type != "string" || s.Format != "date-time" {
+			return mismatch("string date-time")
+		}
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if s.Type != "string" {
+			return mismatch("string")
+		}
+	case reflect.Bool:
+		if s.Type != "boolean" {
+			return mismatch("boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s.Type != "integer" {
+			return mismatch("integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if s.Type != "number" {
+			return mismatch("number")
+		}
+	case reflect.Slice, reflect.Array:
+		if s.Type != "array" || s.Items == nil {
+			return mismatch("array")
+		}
+		return checkSchema(spec, at+"[]", t.Elem(), s.Items)
+	case reflect.Map:
+		if s.Type != "object" || s.AdditionalProperties == nil {
+			return mismatch("object")
+		}
+		return checkSchema(spec, at+"{}", t.Elem(), s.AdditionalProperties)
+	case reflect.Struct:
+		if s.Type != "object" {
+			return mismatch("object")
+		}
+		return checkFields(spec, at, t, s)
+	default:
+		return []string{fmt.Sprintf("%s: %s has no JSON schema", at, t)}
+	}
+	return nil
+}
+
+func checkFields(spec *openAPISpec, at string, t reflect.Type, s *schema) []string {
+	var problems []string
+	fields := make(map[string]bool)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = true
+		property, ok := s.Properties[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: field %s is not documented", at, name))
+			continue
+		}
+		omitempty := slices.Contains(strings.Split(options, ","), "omitempty")
+		required := slices.Contains(s.Required, name)
+		switch {
+		case omitempty && required:
+			problems = append(problems, fmt.Sprintf("%s: %s is omitted when empty but documented as required", at, name))
+		case !omitempty && !required:
+			problems = append(problems, fmt.Sprintf("%s: %s is always sent but not documented as required", at, name))
+		}
+		if !omitempty && field.Type.Kind() == reflect.Pointer && !property.Nullable {
+			problems = append(problems, fmt.Sprintf("%s: %s can be null but is not documented as nullable", at, name))
+		}
+		problems = append(problems, checkSchema(spec, at+"."+name, field.Type, property)...)
+	}
+	for name := range s.Properties {
+		if !fields[name] {
+			problems = append(problems, fmt.Sprintf("%s: property %s is not a field", at, name))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	mathrand "math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	heartbeatInterval = 3 * time.Second
+	registerBaseDelay = time.Second
+	registerMaxDelay  = 30 * time.Second
+)
+
+// registration is the state of the instance in the registry, kept by
+// maintainRegistration.
+var registration struct {
+	mu            sync.Mutex
+	state         string // registering, registered or deregistered
+	attempts      int    // failed attempts since the instance was last registered
+	err           error
+	lastHeartbeat time.Time
+	instances     []Instance // the endpoints maintainRegistration registers
+}
+
+// registry is where the instance registers, chosen by newRegistry.
+var registry Registry
+
+var (
+	stopRegistration    = make(chan struct{})
+	registrationStopped = make(chan struct{})
+)
+
+// maintainRegistration registers the instance's endpoints and keeps them
+// registered until deregister is called. With a registry that needs heartbeats
+// it reports the readiness checks every heartbeatInterval for each endpoint
+// but the gRPC ones, which the registry checks through grpc.health.v1. A
+// failed heartbeat means the registry is unreachable or has lost the instance,
+// as Consul's dev agent does when it restarts, so every endpoint registers
+// again, backing off while the registry keeps refusing.
+func maintainRegistration(instances ...Instance) {
+	defer close(registrationStopped)
+	registration.mu.Lock()
+	registration.instances = instances
+	registration.mu.Unlock()
+	setRegistrationState("registering", nil)
+
+	for {
+		err := registerAll(instances)
+		if err != nil {
+			delay := registrationFailed(err)
+			slog.Warn("Failed to register, retrying", "error", err, "retry_in", delay.String())
+			if !sleepUnlessStopped(delay) {
+				return
+			}
+			continue
+		}
+		consulRegistered.Set(1)
+		setRegistrationState("registered", nil)
+		for _, instance := range instances {
+			slog.Info("Registered", "service", instance.Service, "service_id", instance.ID, "address", instance.HostPort())
+		}
+
+		err = heartbeat(instances)
+		if err == nil {
+			return
+		}
+		consulRegistered.Set(0)
+		setRegistrationState("registering", err)
+		slog.Warn("Lost registration, registering again", "error", err)
+	}
+}
+
+func registerAll(instances []Instance) error {
+	for _, instance := range instances {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		err := registry.Register(ctx, instance)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heartbeat reports readiness for the instances without a gRPC check until
+// deregister is called, which returns nil, or a report fails.
+func heartbeat(instances []Instance) error {
+	hb, ok := registry.(heartbeater)
+	if !ok {
+		<-stopRegistration
+		return nil
+	}
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		checks := readiness(context.Background())
+		ready := healthy(checks)
+		output := "ready"
+		if !ready {
+			output = failingChecks(checks)
+		}
+
+		for _, instance := range instances {
+			if instance.GRPCHealth {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+			err := hb.Heartbeat(ctx, instance.ID, ready, output)
+			cancel()
+			if err != nil {
+				return err
+			}
+		}
+		registration.mu.Lock()
+		registration.lastHeartbeat = time.Now()
+		registration.mu.Unlock()
+
+		select {
+		case <-stopRegistration:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// deregister stops maintainRegistration and removes the instance's endpoints
+// from the registry.
+func deregister() {
+	close(stopRegistration)
+	<-registrationStopped
+
+	registration.mu.Lock()
+	instances := registration.instances
+	registration.mu.Unlock()
+	deregistered := true
+	for _, instance := range instances {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		err := registry.Deregister(ctx, instance.ID)
+		cancel()
+		if err != nil {
+			deregistered = false
+			slog.Error("Failed to deregister", "service_id", instance.ID, "error", err)
+			continue
+		}
+		slog.Info("Deregistered", "service_id", instance.ID)
+	}
+	if deregistered {
+		consulRegistered.Set(0)
+	}
+	setRegistrationState("deregistered", nil)
+}
+
+func sleepUnlessStopped(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-stopRegistration:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func setRegistrationState(state string, err error) {
+	registration.mu.Lock()
+	defer registration.mu.Unlock()
+	registration.state = state
+	registration.err = err
+	if state == "registered" {
+		registration.attempts = 0
+	}
+}
+
+// registrationFailed records a failed attempt and returns how long to wait
+// before the next: one second doubling to 30, with up to 20% jitter.
+func registrationFailed(err error) time.Duration {
+	registration.mu.Lock()
+	defer registration.mu.Unlock()
+	registration.err = err
+	delay := time.Duration(float64(registerBaseDelay) * math.Pow(2, float64(registration.attempts)))
+	if delay > registerMaxDelay || delay <= 0 {
+		delay = registerMaxDelay
+	}
+	registration.attempts++
+	return delay + time.Duration(mathrand.Float64()*0.2*float64(delay))
+}
+
+// registrationCheck reports the registration in readiness. It is not critical:
+// an instance missing from the registry receives no traffic anyway, and
+// failing readiness would not bring the registry back.
+func registrationCheck() Check {
+	registration.mu.Lock()
+	defer registration.mu.Unlock()
+	check := Check{Status: "ok", Attempts: registration.attempts}
+	switch {
+	case registration.state == "registered":
+	case registration.err != nil:
+		check.Status = "failing"
+		check.Error = registration.err.Error()
+	case registration.state == "deregistered":
+		check.Status = "failing"
+		check.Error = "deregistered"
+	default:
+		check.Status = "pending"
+	}
+	if !registration.lastHeartbeat.IsZero() {
+		check.LastHeartbeat = registration.lastHeartbeat.UTC().Format(time.RFC3339)
+	}
+	return check
+}
+
+// failingChecks describes the critical checks that failed, as the output of
+// the heartbeat, which Consul shows on the instance's check.
+func failingChecks(checks map[string]Check) string {
+	var failing []string
+	for name, check := range checks {
+		if check.Critical && check.Status != "ok" {
+			description := name + ": " + check.Status
+			if check.Error != "" {
+				description += " (" + check.Error + ")"
+			}
+			failing = append(failing, description)
+		}
+	}
+	sort.Strings(failing)
+	return strings.Join(failing, "; ")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// The storage functions below are shared by the REST handlers and the gRPC
+// server, so both validate input the same way and publish the same events.
+
+// createTrafficLight stores a new traffic light and publishes its created
+// event.
+func createTrafficLight(ctx context.Context, location, color string) (TrafficLight, error) {
+	var trafficLight TrafficLight
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return trafficLight, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO traffic_lights (location, color) VALUES ($1, $2) RETURNING id, location, color`,
+		location, color,
+	).Scan(&trafficLight.ID, &trafficLight.Location, &trafficLight.Color)
+	if err != nil {
+		return trafficLight, err
+	}
+	if err := publishEvent(tx, "traffic_light", "created", strconv.Itoa(trafficLight.ID), trafficLight); err != nil {
+		return trafficLight, err
+	}
+	return trafficLight, tx.Commit()
+}
+
+// loadTrafficLight returns errNotFound for a traffic light that does not
+// exist.
+func loadTrafficLight(ctx context.Context, id int) (TrafficLight, error) {
+	var trafficLight TrafficLight
+	err := db.QueryRowContext(ctx, `SELECT id, location, color FROM traffic_lights WHERE id = $1`, id).
+		Scan(&trafficLight.ID, &trafficLight.Location, &trafficLight.Color)
+	if err == sql.ErrNoRows {
+		return trafficLight, errNotFound
+	}
+	return trafficLight, err
+}
+
+// setTrafficLightColor changes the color of a traffic light and publishes its
+// updated event.
+func setTrafficLightColor(ctx context.Context, id int, color string) (TrafficLight, error) {
+	var trafficLight TrafficLight
+	if color == "" {
+		return trafficLight, invalidInputError("color is required")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return trafficLight, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`UPDATE traffic_lights SET color = $1 WHERE id = $2 RETURNING id, location, color`, color, id,
+	).Scan(&trafficLight.ID, &trafficLight.Location, &trafficLight.Color)
+	if err == sql.ErrNoRows {
+		return trafficLight, errNotFound
+	}
+	if err != nil {
+		return trafficLight, err
+	}
+	if err := publishEvent(tx, "traffic_light", "updated", strconv.Itoa(trafficLight.ID), trafficLight); err != nil {
+		return trafficLight, err
+	}
+	return trafficLight, tx.Commit()
+}
+
+// removeTrafficLight deletes a traffic light and publishes its deleted event.
+// Deleting one that does not exist is not an error.
+func removeTrafficLight(ctx context.Context, id int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deleted int
+	err = tx.QueryRowContext(ctx, `DELETE FROM traffic_lights WHERE id = $1 RETURNING id`, id).Scan(&deleted)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := publishEvent(tx, "traffic_light", "deleted", strconv.Itoa(deleted), nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loadTrafficLights returns the traffic lights in page p and whether more
+// follow it.
+func loadTrafficLights(ctx context.Context, p page) ([]TrafficLight, bool, error) {
+	clause, args := p.clause(1)
+	rows, err := db.QueryContext(ctx, `SELECT id, location, color FROM traffic_lights`+clause, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var trafficLights []TrafficLight
+	for rows.Next() {
+		var trafficLight TrafficLight
+		if err := rows.Scan(&trafficLight.ID, &trafficLight.Location, &trafficLight.Color); err != nil {
+			return nil, false, err
+		}
+		trafficLights = append(trafficLights, trafficLight)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	if p.more(len(trafficLights)) {
+		return trafficLights[:p.limit], true, nil
+	}
+	return trafficLights, false, nil
+}
@@ -0,0 +1,5 @@
+// Package trafficpb holds the gRPC API of the Traffic service, generated from
+// traffic.proto.
+package trafficpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative traffic.proto
@@ -0,0 +1,745 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: traffic.proto
+
+package trafficpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Color int32
+
+const (
+	Color_COLOR_UNSPECIFIED Color = 0
+	Color_COLOR_RED         Color = 1
+	Color_COLOR_YELLOW      Color = 2
+	Color_COLOR_GREEN       Color = 3
+)
+
+// Enum value maps for Color.
+var (
+	Color_name = map[int32]string{
+		0: "COLOR_UNSPECIFIED",
+		1: "COLOR_RED",
+		2: "COLOR_YELLOW",
+		3: "COLOR_GREEN",
+	}
+	Color_value = map[string]int32{
+		"COLOR_UNSPECIFIED": 0,
+		"COLOR_RED":         1,
+		"COLOR_YELLOW":      2,
+		"COLOR_GREEN":       3,
+	}
+)
+
+func (x Color) Enum() *Color {
+	p := new(Color)
+	*p = x
+	return p
+}
+
+func (x Color) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Color) Descriptor() protoreflect.EnumDescriptor {
+	return file_traffic_proto_enumTypes[0].Descriptor()
+}
+
+func (Color) Type() protoreflect.EnumType {
+	return &file_traffic_proto_enumTypes[0]
+}
+
+func (x Color) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Color.Descriptor instead.
+func (Color) EnumDescriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{0}
+}
+
+type Action int32
+
+const (
+	Action_ACTION_UNSPECIFIED Action = 0
+	Action_ACTION_CREATED     Action = 1
+	Action_ACTION_UPDATED     Action = 2
+	Action_ACTION_DELETED     Action = 3
+)
+
+// Enum value maps for Action.
+var (
+	Action_name = map[int32]string{
+		0: "ACTION_UNSPECIFIED",
+		1: "ACTION_CREATED",
+		2: "ACTION_UPDATED",
+		3: "ACTION_DELETED",
+	}
+	Action_value = map[string]int32{
+		"ACTION_UNSPECIFIED": 0,
+		"ACTION_CREATED":     1,
+		"ACTION_UPDATED":     2,
+		"ACTION_DELETED":     3,
+	}
+)
+
+func (x Action) Enum() *Action {
+	p := new(Action)
+	*p = x
+	return p
+}
+
+func (x Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_traffic_proto_enumTypes[1].Descriptor()
+}
+
+func (Action) Type() protoreflect.EnumType {
+	return &file_traffic_proto_enumTypes[1]
+}
+
+func (x Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Action.Descriptor instead.
+func (Action) EnumDescriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{1}
+}
+
+type TrafficLight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Location      string                 `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	Color         Color                  `protobuf:"varint,3,opt,name=color,proto3,enum=metagrid.traffic.v1.Color" json:"color,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficLight) Reset() {
+	*x = TrafficLight{}
+	mi := &file_traffic_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficLight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficLight) ProtoMessage() {}
+
+func (x *TrafficLight) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficLight.ProtoReflect.Descriptor instead.
+func (*TrafficLight) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TrafficLight) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TrafficLight) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *TrafficLight) GetColor() Color {
+	if x != nil {
+		return x.Color
+	}
+	return Color_COLOR_UNSPECIFIED
+}
+
+type CreateTrafficLightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      string                 `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Color         Color                  `protobuf:"varint,2,opt,name=color,proto3,enum=metagrid.traffic.v1.Color" json:"color,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTrafficLightRequest) Reset() {
+	*x = CreateTrafficLightRequest{}
+	mi := &file_traffic_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTrafficLightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTrafficLightRequest) ProtoMessage() {}
+
+func (x *CreateTrafficLightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTrafficLightRequest.ProtoReflect.Descriptor instead.
+func (*CreateTrafficLightRequest) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTrafficLightRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *CreateTrafficLightRequest) GetColor() Color {
+	if x != nil {
+		return x.Color
+	}
+	return Color_COLOR_UNSPECIFIED
+}
+
+type GetTrafficLightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrafficLightRequest) Reset() {
+	*x = GetTrafficLightRequest{}
+	mi := &file_traffic_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrafficLightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrafficLightRequest) ProtoMessage() {}
+
+func (x *GetTrafficLightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrafficLightRequest.ProtoReflect.Descriptor instead.
+func (*GetTrafficLightRequest) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetTrafficLightRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UpdateTrafficLightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Color         Color                  `protobuf:"varint,2,opt,name=color,proto3,enum=metagrid.traffic.v1.Color" json:"color,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTrafficLightRequest) Reset() {
+	*x = UpdateTrafficLightRequest{}
+	mi := &file_traffic_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTrafficLightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTrafficLightRequest) ProtoMessage() {}
+
+func (x *UpdateTrafficLightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTrafficLightRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTrafficLightRequest) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateTrafficLightRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTrafficLightRequest) GetColor() Color {
+	if x != nil {
+		return x.Color
+	}
+	return Color_COLOR_UNSPECIFIED
+}
+
+type DeleteTrafficLightRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTrafficLightRequest) Reset() {
+	*x = DeleteTrafficLightRequest{}
+	mi := &file_traffic_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTrafficLightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTrafficLightRequest) ProtoMessage() {}
+
+func (x *DeleteTrafficLightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTrafficLightRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTrafficLightRequest) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteTrafficLightRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListTrafficLightsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size limits the response to that many traffic lights, ordered by
+	// id. Without it every traffic light is returned.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// after_id starts the page after the traffic light with that id.
+	AfterId       int32 `protobuf:"varint,2,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrafficLightsRequest) Reset() {
+	*x = ListTrafficLightsRequest{}
+	mi := &file_traffic_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrafficLightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrafficLightsRequest) ProtoMessage() {}
+
+func (x *ListTrafficLightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrafficLightsRequest.ProtoReflect.Descriptor instead.
+func (*ListTrafficLightsRequest) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListTrafficLightsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTrafficLightsRequest) GetAfterId() int32 {
+	if x != nil {
+		return x.AfterId
+	}
+	return 0
+}
+
+type ListTrafficLightsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrafficLights []*TrafficLight        `protobuf:"bytes,1,rep,name=traffic_lights,json=trafficLights,proto3" json:"traffic_lights,omitempty"`
+	// next_after_id is the after_id of the next page, or 0 on the last one.
+	NextAfterId   int32 `protobuf:"varint,2,opt,name=next_after_id,json=nextAfterId,proto3" json:"next_after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrafficLightsResponse) Reset() {
+	*x = ListTrafficLightsResponse{}
+	mi := &file_traffic_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrafficLightsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrafficLightsResponse) ProtoMessage() {}
+
+func (x *ListTrafficLightsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrafficLightsResponse.ProtoReflect.Descriptor instead.
+func (*ListTrafficLightsResponse) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListTrafficLightsResponse) GetTrafficLights() []*TrafficLight {
+	if x != nil {
+		return x.TrafficLights
+	}
+	return nil
+}
+
+func (x *ListTrafficLightsResponse) GetNextAfterId() int32 {
+	if x != nil {
+		return x.NextAfterId
+	}
+	return 0
+}
+
+type WatchTrafficLightsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When set, the events published after this one are sent first.
+	SinceEventId  *int64 `protobuf:"varint,1,opt,name=since_event_id,json=sinceEventId,proto3,oneof" json:"since_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTrafficLightsRequest) Reset() {
+	*x = WatchTrafficLightsRequest{}
+	mi := &file_traffic_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTrafficLightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTrafficLightsRequest) ProtoMessage() {}
+
+func (x *WatchTrafficLightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTrafficLightsRequest.ProtoReflect.Descriptor instead.
+func (*WatchTrafficLightsRequest) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchTrafficLightsRequest) GetSinceEventId() int64 {
+	if x != nil && x.SinceEventId != nil {
+		return *x.SinceEventId
+	}
+	return 0
+}
+
+type TrafficLightEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	EventId int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Action  Action                 `protobuf:"varint,2,opt,name=action,proto3,enum=metagrid.traffic.v1.Action" json:"action,omitempty"`
+	Id      int32                  `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	// The traffic light after the change; unset when it was deleted.
+	TrafficLight  *TrafficLight          `protobuf:"bytes,4,opt,name=traffic_light,json=trafficLight,proto3" json:"traffic_light,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficLightEvent) Reset() {
+	*x = TrafficLightEvent{}
+	mi := &file_traffic_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficLightEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficLightEvent) ProtoMessage() {}
+
+func (x *TrafficLightEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_traffic_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficLightEvent.ProtoReflect.Descriptor instead.
+func (*TrafficLightEvent) Descriptor() ([]byte, []int) {
+	return file_traffic_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TrafficLightEvent) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *TrafficLightEvent) GetAction() Action {
+	if x != nil {
+		return x.Action
+	}
+	return Action_ACTION_UNSPECIFIED
+}
+
+func (x *TrafficLightEvent) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TrafficLightEvent) GetTrafficLight() *TrafficLight {
+	if x != nil {
+		return x.TrafficLight
+	}
+	return nil
+}
+
+func (x *TrafficLightEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_traffic_proto protoreflect.FileDescriptor
+
+const file_traffic_proto_rawDesc = "" +
+	"\n" +
+	"\rtraffic.proto\x12\x13metagrid.traffic.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"l\n" +
+	"\fTrafficLight\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1a\n" +
+	"\blocation\x18\x02 \x01(\tR\blocation\x120\n" +
+	"\x05color\x18\x03 \x01(\x0e2\x1a.metagrid.traffic.v1.ColorR\x05color\"i\n" +
+	"\x19CreateTrafficLightRequest\x12\x1a\n" +
+	"\blocation\x18\x01 \x01(\tR\blocation\x120\n" +
+	"\x05color\x18\x02 \x01(\x0e2\x1a.metagrid.traffic.v1.ColorR\x05color\"(\n" +
+	"\x16GetTrafficLightRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"]\n" +
+	"\x19UpdateTrafficLightRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x120\n" +
+	"\x05color\x18\x02 \x01(\x0e2\x1a.metagrid.traffic.v1.ColorR\x05color\"+\n" +
+	"\x19DeleteTrafficLightRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"R\n" +
+	"\x18ListTrafficLightsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x19\n" +
+	"\bafter_id\x18\x02 \x01(\x05R\aafterId\"\x89\x01\n" +
+	"\x19ListTrafficLightsResponse\x12H\n" +
+	"\x0etraffic_lights\x18\x01 \x03(\v2!.metagrid.traffic.v1.TrafficLightR\rtrafficLights\x12\"\n" +
+	"\rnext_after_id\x18\x02 \x01(\x05R\vnextAfterId\"Y\n" +
+	"\x19WatchTrafficLightsRequest\x12)\n" +
+	"\x0esince_event_id\x18\x01 \x01(\x03H\x00R\fsinceEventId\x88\x01\x01B\x11\n" +
+	"\x0f_since_event_id\"\xf6\x01\n" +
+	"\x11TrafficLightEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x123\n" +
+	"\x06action\x18\x02 \x01(\x0e2\x1b.metagrid.traffic.v1.ActionR\x06action\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\x05R\x02id\x12F\n" +
+	"\rtraffic_light\x18\x04 \x01(\v2!.metagrid.traffic.v1.TrafficLightR\ftrafficLight\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt*P\n" +
+	"\x05Color\x12\x15\n" +
+	"\x11COLOR_UNSPECIFIED\x10\x00\x12\r\n" +
+	"\tCOLOR_RED\x10\x01\x12\x10\n" +
+	"\fCOLOR_YELLOW\x10\x02\x12\x0f\n" +
+	"\vCOLOR_GREEN\x10\x03*\\\n" +
+	"\x06Action\x12\x16\n" +
+	"\x12ACTION_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eACTION_CREATED\x10\x01\x12\x12\n" +
+	"\x0eACTION_UPDATED\x10\x02\x12\x12\n" +
+	"\x0eACTION_DELETED\x10\x032\x86\x05\n" +
+	"\rTrafficLights\x12g\n" +
+	"\x12CreateTrafficLight\x12..metagrid.traffic.v1.CreateTrafficLightRequest\x1a!.metagrid.traffic.v1.TrafficLight\x12a\n" +
+	"\x0fGetTrafficLight\x12+.metagrid.traffic.v1.GetTrafficLightRequest\x1a!.metagrid.traffic.v1.TrafficLight\x12g\n" +
+	"\x12UpdateTrafficLight\x12..metagrid.traffic.v1.UpdateTrafficLightRequest\x1a!.metagrid.traffic.v1.TrafficLight\x12\\\n" +
+	"\x12DeleteTrafficLight\x12..metagrid.traffic.v1.DeleteTrafficLightRequest\x1a\x16.google.protobuf.Empty\x12r\n" +
+	"\x11ListTrafficLights\x12-.metagrid.traffic.v1.ListTrafficLightsRequest\x1a..metagrid.traffic.v1.ListTrafficLightsResponse\x12n\n" +
+	"\x12WatchTrafficLights\x12..metagrid.traffic.v1.WatchTrafficLightsRequest\x1a&.metagrid.traffic.v1.TrafficLightEvent0\x01B\"Z metagrid/trafficLights/trafficpbb\x06proto3"
+
+var (
+	file_traffic_proto_rawDescOnce sync.Once
+	file_traffic_proto_rawDescData []byte
+)
+
+func file_traffic_proto_rawDescGZIP() []byte {
+	file_traffic_proto_rawDescOnce.Do(func() {
+		file_traffic_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_traffic_proto_rawDesc), len(file_traffic_proto_rawDesc)))
+	})
+	return file_traffic_proto_rawDescData
+}
+
+var file_traffic_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_traffic_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_traffic_proto_goTypes = []any{
+	(Color)(0),                        // 0: metagrid.traffic.v1.Color
+	(Action)(0),                       // 1: metagrid.traffic.v1.Action
+	(*TrafficLight)(nil),              // 2: metagrid.traffic.v1.TrafficLight
+	(*CreateTrafficLightRequest)(nil), // 3: metagrid.traffic.v1.CreateTrafficLightRequest
+	(*GetTrafficLightRequest)(nil),    // 4: metagrid.traffic.v1.GetTrafficLightRequest
+	(*UpdateTrafficLightRequest)(nil), // 5: metagrid.traffic.v1.UpdateTrafficLightRequest
+	(*DeleteTrafficLightRequest)(nil), // 6: metagrid.traffic.v1.DeleteTrafficLightRequest
+	(*ListTrafficLightsRequest)(nil),  // 7: metagrid.traffic.v1.ListTrafficLightsRequest
+	(*ListTrafficLightsResponse)(nil), // 8: metagrid.traffic.v1.ListTrafficLightsResponse
+	(*WatchTrafficLightsRequest)(nil), // 9: metagrid.traffic.v1.WatchTrafficLightsRequest
+	(*TrafficLightEvent)(nil),         // 10: metagrid.traffic.v1.TrafficLightEvent
+	(*timestamppb.Timestamp)(nil),     // 11: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),             // 12: google.protobuf.Empty
+}
+var file_traffic_proto_depIdxs = []int32{
+	0,  // 0: metagrid.traffic.v1.TrafficLight.color:type_name -> metagrid.traffic.v1.Color
+	0,  // 1: metagrid.traffic.v1.CreateTrafficLightRequest.color:type_name -> metagrid.traffic.v1.Color
+	0,  // 2: metagrid.traffic.v1.UpdateTrafficLightRequest.color:type_name -> metagrid.traffic.v1.Color
+	2,  // 3: metagrid.traffic.v1.ListTrafficLightsResponse.traffic_lights:type_name -> metagrid.traffic.v1.TrafficLight
+	1,  // 4: metagrid.traffic.v1.TrafficLightEvent.action:type_name -> metagrid.traffic.v1.Action
+	2,  // 5: metagrid.traffic.v1.TrafficLightEvent.traffic_light:type_name -> metagrid.traffic.v1.TrafficLight
+	11, // 6: metagrid.traffic.v1.TrafficLightEvent.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 7: metagrid.traffic.v1.TrafficLights.CreateTrafficLight:input_type -> metagrid.traffic.v1.CreateTrafficLightRequest
+	4,  // 8: metagrid.traffic.v1.TrafficLights.GetTrafficLight:input_type -> metagrid.traffic.v1.GetTrafficLightRequest
+	5,  // 9: metagrid.traffic.v1.TrafficLights.UpdateTrafficLight:input_type -> metagrid.traffic.v1.UpdateTrafficLightRequest
+	6,  // 10: metagrid.traffic.v1.TrafficLights.DeleteTrafficLight:input_type -> metagrid.traffic.v1.DeleteTrafficLightRequest
+	7,  // 11: metagrid.traffic.v1.TrafficLights.ListTrafficLights:input_type -> metagrid.traffic.v1.ListTrafficLightsRequest
+	9,  // 12: metagrid.traffic.v1.TrafficLights.WatchTrafficLights:input_type -> metagrid.traffic.v1.WatchTrafficLightsRequest
+	2,  // 13: metagrid.traffic.v1.TrafficLights.CreateTrafficLight:output_type -> metagrid.traffic.v1.TrafficLight
+	2,  // 14: metagrid.traffic.v1.TrafficLights.GetTrafficLight:output_type -> metagrid.traffic.v1.TrafficLight
+	2,  // 15: metagrid.traffic.v1.TrafficLights.UpdateTrafficLight:output_type -> metagrid.traffic.v1.TrafficLight
+	12, // 16: metagrid.traffic.v1.TrafficLights.DeleteTrafficLight:output_type -> google.protobuf.Empty
+	8,  // 17: metagrid.traffic.v1.TrafficLights.ListTrafficLights:output_type -> metagrid.traffic.v1.ListTrafficLightsResponse
+	10, // 18: metagrid.traffic.v1.TrafficLights.WatchTrafficLights:output_type -> metagrid.traffic.v1.TrafficLightEvent
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_traffic_proto_init() }
+func file_traffic_proto_init() {
+	if File_traffic_proto != nil {
+		return
+	}
+	file_traffic_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_traffic_proto_rawDesc), len(file_traffic_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_traffic_proto_goTypes,
+		DependencyIndexes: file_traffic_proto_depIdxs,
+		EnumInfos:         file_traffic_proto_enumTypes,
+		MessageInfos:      file_traffic_proto_msgTypes,
+	}.Build()
+	File_traffic_proto = out.File
+	file_traffic_proto_goTypes = nil
+	file_traffic_proto_depIdxs = nil
+}
@@ -0,0 +1,336 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: traffic.proto
+
+package trafficpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TrafficLights_CreateTrafficLight_FullMethodName = "/metagrid.traffic.v1.TrafficLights/CreateTrafficLight"
+	TrafficLights_GetTrafficLight_FullMethodName    = "/metagrid.traffic.v1.TrafficLights/GetTrafficLight"
+	TrafficLights_UpdateTrafficLight_FullMethodName = "/metagrid.traffic.v1.TrafficLights/UpdateTrafficLight"
+	TrafficLights_DeleteTrafficLight_FullMethodName = "/metagrid.traffic.v1.TrafficLights/DeleteTrafficLight"
+	TrafficLights_ListTrafficLights_FullMethodName  = "/metagrid.traffic.v1.TrafficLights/ListTrafficLights"
+	TrafficLights_WatchTrafficLights_FullMethodName = "/metagrid.traffic.v1.TrafficLights/WatchTrafficLights"
+)
+
+// TrafficLightsClient is the client API for TrafficLights service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TrafficLights is the gRPC API of the Traffic service. It serves the same
+// traffic lights as the REST API, with the same validation, and streams their
+// changes from the same event log as /events.
+type TrafficLightsClient interface {
+	CreateTrafficLight(ctx context.Context, in *CreateTrafficLightRequest, opts ...grpc.CallOption) (*TrafficLight, error)
+	GetTrafficLight(ctx context.Context, in *GetTrafficLightRequest, opts ...grpc.CallOption) (*TrafficLight, error)
+	UpdateTrafficLight(ctx context.Context, in *UpdateTrafficLightRequest, opts ...grpc.CallOption) (*TrafficLight, error)
+	// DeleteTrafficLight succeeds for a traffic light that does not exist, as
+	// DELETE does.
+	DeleteTrafficLight(ctx context.Context, in *DeleteTrafficLightRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListTrafficLights(ctx context.Context, in *ListTrafficLightsRequest, opts ...grpc.CallOption) (*ListTrafficLightsResponse, error)
+	// WatchTrafficLights streams every change to a traffic light until the
+	// client cancels. It ends with UNAVAILABLE when the instance shuts down or
+	// the client falls too far behind; the client then resumes from the last
+	// event it received with since_event_id.
+	WatchTrafficLights(ctx context.Context, in *WatchTrafficLightsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TrafficLightEvent], error)
+}
+
+type trafficLightsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrafficLightsClient(cc grpc.ClientConnInterface) TrafficLightsClient {
+	return &trafficLightsClient{cc}
+}
+
+func (c *trafficLightsClient) CreateTrafficLight(ctx context.Context, in *CreateTrafficLightRequest, opts ...grpc.CallOption) (*TrafficLight, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrafficLight)
+	err := c.cc.Invoke(ctx, TrafficLights_CreateTrafficLight_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficLightsClient) GetTrafficLight(ctx context.Context, in *GetTrafficLightRequest, opts ...grpc.CallOption) (*TrafficLight, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrafficLight)
+	err := c.cc.Invoke(ctx, TrafficLights_GetTrafficLight_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficLightsClient) UpdateTrafficLight(ctx context.Context, in *UpdateTrafficLightRequest, opts ...grpc.CallOption) (*TrafficLight, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrafficLight)
+	err := c.cc.Invoke(ctx, TrafficLights_UpdateTrafficLight_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficLightsClient) DeleteTrafficLight(ctx context.Context, in *DeleteTrafficLightRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TrafficLights_DeleteTrafficLight_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficLightsClient) ListTrafficLights(ctx context.Context, in *ListTrafficLightsRequest, opts ...grpc.CallOption) (*ListTrafficLightsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrafficLightsResponse)
+	err := c.cc.Invoke(ctx, TrafficLights_ListTrafficLights_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trafficLightsClient) WatchTrafficLights(ctx context.Context, in *WatchTrafficLightsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TrafficLightEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TrafficLights_ServiceDesc.Streams[0], TrafficLights_WatchTrafficLights_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTrafficLightsRequest, TrafficLightEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TrafficLights_WatchTrafficLightsClient = grpc.ServerStreamingClient[TrafficLightEvent]
+
+// TrafficLightsServer is the server API for TrafficLights service.
+// All implementations must embed UnimplementedTrafficLightsServer
+// for forward compatibility.
+//
+// TrafficLights is the gRPC API of the Traffic service. It serves the same
+// traffic lights as the REST API, with the same validation, and streams their
+// changes from the same event log as /events.
+type TrafficLightsServer interface {
+	CreateTrafficLight(context.Context, *CreateTrafficLightRequest) (*TrafficLight, error)
+	GetTrafficLight(context.Context, *GetTrafficLightRequest) (*TrafficLight, error)
+	UpdateTrafficLight(context.Context, *UpdateTrafficLightRequest) (*TrafficLight, error)
+	// DeleteTrafficLight succeeds for a traffic light that does not exist, as
+	// DELETE does.
+	DeleteTrafficLight(context.Context, *DeleteTrafficLightRequest) (*emptypb.Empty, error)
+	ListTrafficLights(context.Context, *ListTrafficLightsRequest) (*ListTrafficLightsResponse, error)
+	// WatchTrafficLights streams every change to a traffic light until the
+	// client cancels. It ends with UNAVAILABLE when the instance shuts down or
+	// the client falls too far behind; the client then resumes from the last
+	// event it received with since_event_id.
+	WatchTrafficLights(*WatchTrafficLightsRequest, grpc.ServerStreamingServer[TrafficLightEvent]) error
+	mustEmbedUnimplementedTrafficLightsServer()
+}
+
+// UnimplementedTrafficLightsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTrafficLightsServer struct{}
+
+func (UnimplementedTrafficLightsServer) CreateTrafficLight(context.Context, *CreateTrafficLightRequest) (*TrafficLight, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTrafficLight not implemented")
+}
+func (UnimplementedTrafficLightsServer) GetTrafficLight(context.Context, *GetTrafficLightRequest) (*TrafficLight, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrafficLight not implemented")
+}
+func (UnimplementedTrafficLightsServer) UpdateTrafficLight(context.Context, *UpdateTrafficLightRequest) (*TrafficLight, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTrafficLight not implemented")
+}
+func (UnimplementedTrafficLightsServer) DeleteTrafficLight(context.Context, *DeleteTrafficLightRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTrafficLight not implemented")
+}
+func (UnimplementedTrafficLightsServer) ListTrafficLights(context.Context, *ListTrafficLightsRequest) (*ListTrafficLightsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrafficLights not implemented")
+}
+func (UnimplementedTrafficLightsServer) WatchTrafficLights(*WatchTrafficLightsRequest, grpc.ServerStreamingServer[TrafficLightEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTrafficLights not implemented")
+}
+func (UnimplementedTrafficLightsServer) mustEmbedUnimplementedTrafficLightsServer() {}
+func (UnimplementedTrafficLightsServer) testEmbeddedByValue()                       {}
+
+// UnsafeTrafficLightsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TrafficLightsServer will
+// result in compilation errors.
+type UnsafeTrafficLightsServer interface {
+	mustEmbedUnimplementedTrafficLightsServer()
+}
+
+func RegisterTrafficLightsServer(s grpc.ServiceRegistrar, srv TrafficLightsServer) {
+	// If the following call pancis, it indicates UnimplementedTrafficLightsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TrafficLights_ServiceDesc, srv)
+}
+
+func _TrafficLights_CreateTrafficLight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTrafficLightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficLightsServer).CreateTrafficLight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrafficLights_CreateTrafficLight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficLightsServer).CreateTrafficLight(ctx, req.(*CreateTrafficLightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficLights_GetTrafficLight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrafficLightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficLightsServer).GetTrafficLight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrafficLights_GetTrafficLight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficLightsServer).GetTrafficLight(ctx, req.(*GetTrafficLightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficLights_UpdateTrafficLight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTrafficLightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficLightsServer).UpdateTrafficLight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrafficLights_UpdateTrafficLight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficLightsServer).UpdateTrafficLight(ctx, req.(*UpdateTrafficLightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficLights_DeleteTrafficLight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTrafficLightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficLightsServer).DeleteTrafficLight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrafficLights_DeleteTrafficLight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficLightsServer).DeleteTrafficLight(ctx, req.(*DeleteTrafficLightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficLights_ListTrafficLights_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrafficLightsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrafficLightsServer).ListTrafficLights(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrafficLights_ListTrafficLights_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrafficLightsServer).ListTrafficLights(ctx, req.(*ListTrafficLightsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrafficLights_WatchTrafficLights_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTrafficLightsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TrafficLightsServer).WatchTrafficLights(m, &grpc.GenericServerStream[WatchTrafficLightsRequest, TrafficLightEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TrafficLights_WatchTrafficLightsServer = grpc.ServerStreamingServer[TrafficLightEvent]
+
+// TrafficLights_ServiceDesc is the grpc.ServiceDesc for TrafficLights service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TrafficLights_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "metagrid.traffic.v1.TrafficLights",
+	HandlerType: (*TrafficLightsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTrafficLight",
+			Handler:    _TrafficLights_CreateTrafficLight_Handler,
+		},
+		{
+			MethodName: "GetTrafficLight",
+			Handler:    _TrafficLights_GetTrafficLight_Handler,
+		},
+		{
+			MethodName: "UpdateTrafficLight",
+			Handler:    _TrafficLights_UpdateTrafficLight_Handler,
+		},
+		{
+			MethodName: "DeleteTrafficLight",
+			Handler:    _TrafficLights_DeleteTrafficLight_Handler,
+		},
+		{
+			MethodName: "ListTrafficLights",
+			Handler:    _TrafficLights_ListTrafficLights_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTrafficLights",
+			Handler:       _TrafficLights_WatchTrafficLights_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "traffic.proto",
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// rollupGranularity is a fixed bucket size that weather_rollups is kept at.
+type rollupGranularity struct {
+	Name     string // value stored in weather_rollups.granularity
+	Trunc    string // date_trunc field matching Size
+	Size     time.Duration
+	Interval string // Postgres interval literal for Size
+}
+
+// rollupGranularities is ordered from finest to coarsest.
+var rollupGranularities = []rollupGranularity{
+	{"1h", "hour", time.Hour, "1 hour"},
+	{"1d", "day", 24 * time.Hour, "1 day"},
+}
+
+const maxAggregateBuckets = 10000
+
+var aggregateStats = map[string]bool{"avg": true, "min": true, "max": true, "sum": true, "count": true}
+
+const rollupsTable = `CREATE TABLE IF NOT EXISTS weather_rollups (
+	station_id TEXT NOT NULL REFERENCES stations (id) ON DELETE CASCADE,
+	granularity TEXT NOT NULL,
+	bucket_start TIMESTAMP NOT NULL,
+	metric TEXT NOT NULL,
+	count BIGINT NOT NULL,
+	sum DOUBLE PRECISION NOT NULL,
+	min DOUBLE PRECISION NOT NULL,
+	max DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (station_id, granularity, bucket_start, metric)
+)`
+
+// rollupSelect returns a query producing weather_rollups rows at granularity
+// g for every metric from the observations matching where.
+func rollupSelect(g rollupGranularity, where string) string {
+	parts := make([]string, 0, len(observationMetrics))
+	for _, m := range observationMetrics {
+		parts = append(parts, fmt.Sprintf(
+			`SELECT station_id, '%s', date_trunc('%s', observed_at), '%s', COUNT(%s), SUM(%s), MIN(%s), MAX(%s)
+			FROM observations
+			WHERE %s IS NOT NULL AND %s_quality IN ('%s') AND (%s)
+			GROUP BY station_id, date_trunc('%s', observed_at)`,
+			g.Name, g.Trunc, m.Name, m.Name, m.Name, m.Name, m.Name,
+			m.Name, m.Name, strings.Join(trustedQualities, "', '"), where, g.Trunc,
+		))
+	}
+	return strings.Join(parts, "\nUNION ALL\n")
+}
+
+// rebuildRollupsIfEmpty backfills weather_rollups from existing observations
+// the first time the table is created.
+func rebuildRollupsIfEmpty(tx *sql.Tx) error {
+	var populated bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM weather_rollups)`).Scan(&populated); err != nil {
+		return err
+	}
+	if populated {
+		return nil
+	}
+	for _, g := range rollupGranularities {
+		query := `INSERT INTO weather_rollups (station_id, granularity, bucket_start, metric, count, sum, min, max) ` + rollupSelect(g, "TRUE")
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addToRollups folds a newly inserted observation into every rollup bucket it
+// belongs to.
+func addToRollups(tx *sql.Tx, o *Observation) error {
+	var values []string
+	var args []any
+	for i, m := range o.measurements() {
+		if *m == nil || !trustedQuality((*m).Quality) {
+			continue
+		}
+		for _, g := range rollupGranularities {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, 1, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+5, n+5))
+			args = append(args, o.StationID, g.Name, o.ObservedAt.UTC().Truncate(g.Size), observationMetrics[i].Name, (*m).Value)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`INSERT INTO weather_rollups (station_id, granularity, bucket_start, metric, count, sum, min, max)
+		VALUES `+strings.Join(values, ", ")+`
+		ON CONFLICT (station_id, granularity, bucket_start, metric) DO UPDATE SET
+			count = weather_rollups.count + EXCLUDED.count,
+			sum = weather_rollups.sum + EXCLUDED.sum,
+			min = LEAST(weather_rollups.min, EXCLUDED.min),
+			max = GREATEST(weather_rollups.max, EXCLUDED.max)`, args...)
+	return err
+}
+
+// refreshRollups recomputes the rollup buckets containing observedAt for a
+// station. Updates and deletes use it since min and max cannot be undone
+// incrementally.
+func refreshRollups(tx *sql.Tx, stationID string, observedAt time.Time) error {
+	for _, g := range rollupGranularities {
+		start := observedAt.UTC().Truncate(g.Size)
+		if _, err := tx.Exec(
+			`DELETE FROM weather_rollups WHERE station_id = $1 AND granularity = $2 AND bucket_start = $3`,
+			stationID, g.Name, start,
+		); err != nil {
+			return err
+		}
+		query := `INSERT INTO weather_rollups (station_id, granularity, bucket_start, metric, count, sum, min, max) ` +
+			rollupSelect(g, "station_id = $1 AND observed_at >= $2 AND observed_at < $3")
+		if _, err := tx.Exec(query, stationID, start, start.Add(g.Size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partialAggregate holds mergeable statistics for one metric in one bucket.
+type partialAggregate struct {
+	Count int64
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+func (p *partialAggregate) merge(o partialAggregate) {
+	if o.Count == 0 {
+		return
+	}
+	if p.Count == 0 {
+		*p = o
+		return
+	}
+	p.Count += o.Count
+	p.Sum += o.Sum
+	p.Min = math.Min(p.Min, o.Min)
+	p.Max = math.Max(p.Max, o.Max)
+}
+
+// stat returns the named statistic converted with c. Sums only scale, as the
+// offset applies once per value rather than once per bucket.
+func (p partialAggregate) stat(name string, c unitConversion) float64 {
+	switch name {
+	case "avg":
+		return c.from(p.Sum / float64(p.Count))
+	case "min":
+		return c.from(p.Min)
+	case "max":
+		return c.from(p.Max)
+	case "sum":
+		return c.from(p.Sum) + c.Offset*float64(p.Count-1)
+	}
+	return float64(p.Count)
+}
+
+// AggregateBucket holds the requested statistics per metric for one bucket.
+type AggregateBucket struct {
+	Start   time.Time                     `json:"start"`
+	Metrics map[string]map[string]float64 `json:"metrics"`
+}
+
+// aggregateKey identifies a metric within an output bucket.
+type aggregateKey struct {
+	Bucket int64
+	Metric string
+}
+
+// WeatherAggregate is the response of GET /weather/aggregate.
+type WeatherAggregate struct {
+	StationID string            `json:"station_id"`
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Bucket    string            `json:"bucket"`
+	Units     map[string]string `json:"units"`
+	Buckets   []AggregateBucket `json:"buckets"`
+}
+
+// aggregateWeather serves GET /weather/aggregate. Whole hours or days inside
+// the range are read from weather_rollups; the ragged edges and bucket sizes
+// that are not a multiple of a rollup granularity fall back to observations.
+func aggregateWeather(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	location := query.Get("location")
+	if location == "" {
+		http.Error(w, "location query parameter is required", http.StatusBadRequest)
+		return
+	}
+	stationID, err := resolveStation(location)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to resolve station", http.StatusInternalServerError)
+		return
+	}
+
+	from, to, err := parseTimeRange(query.Get("from"), query.Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bucketStr := query.Get("bucket")
+	if bucketStr == "" {
+		bucketStr = "1h"
+	}
+	bucket, err := parseBucket(bucketStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if to.Sub(from)/bucket > maxAggregateBuckets {
+		http.Error(w, fmt.Sprintf("Range too large, at most %d buckets allowed", maxAggregateBuckets), http.StatusBadRequest)
+		return
+	}
+
+	stats := splitList(query.Get("metrics"), "avg,min,max")
+	for _, s := range stats {
+		if !aggregateStats[s] {
+			http.Error(w, fmt.Sprintf("Unknown statistic %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+	fields := splitList(query.Get("fields"), "temperature")
+	for _, f := range fields {
+		if metricIndex(f) < 0 {
+			http.Error(w, fmt.Sprintf("Unknown field %q", f), http.StatusBadRequest)
+			return
+		}
+	}
+
+	partials, err := aggregateObservations(stationID, from, to, bucket, fields)
+	if err != nil {
+		http.Error(w, "Failed to aggregate weather entries", http.StatusInternalServerError)
+		return
+	}
+
+	units := requestUnits(r)
+	byBucket := map[int64]*AggregateBucket{}
+	for key, p := range partials {
+		b, ok := byBucket[key.Bucket]
+		if !ok {
+			b = &AggregateBucket{
+				Start:   time.Unix(key.Bucket*int64(bucket/time.Second), 0).UTC(),
+				Metrics: map[string]map[string]float64{},
+			}
+			byBucket[key.Bucket] = b
+		}
+		c := conversion(units, key.Metric)
+		values := map[string]float64{}
+		for _, s := range stats {
+			values[s] = p.stat(s, c)
+		}
+		b.Metrics[key.Metric] = values
+	}
+	buckets := make([]AggregateBucket, 0, len(byBucket))
+	for _, b := range byBucket {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+
+	response := WeatherAggregate{stationID, from, to, bucketStr, map[string]string{}, buckets}
+	for _, f := range fields {
+		response.Units[f] = conversion(units, f).Unit
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// aggregateObservations computes per-bucket partial aggregates for fields of
+// a station over [from, to). Buckets are aligned to the Unix epoch.
+func aggregateObservations(stationID string, from, to time.Time, bucket time.Duration, fields []string) (map[aggregateKey]*partialAggregate, error) {
+	result := map[aggregateKey]*partialAggregate{}
+	add := func(rows *sql.Rows) error {
+		defer rows.Close()
+		for rows.Next() {
+			var key aggregateKey
+			var p partialAggregate
+			if err := rows.Scan(&key.Bucket, &key.Metric, &p.Count, &p.Sum, &p.Min, &p.Max); err != nil {
+				return err
+			}
+			if existing, ok := result[key]; ok {
+				existing.merge(p)
+			} else {
+				result[key] = &p
+			}
+		}
+		return rows.Err()
+	}
+
+	rawRanges := [][2]time.Time{{from, to}}
+	if g, ok := rollupFor(bucket); ok {
+		innerFrom := ceilTime(from, g.Size)
+		innerTo := to.UTC().Truncate(g.Size)
+		if innerFrom.Before(innerTo) {
+			rows, err := db.Query(
+				`SELECT floor(extract(epoch FROM bucket_start) / $1)::bigint, metric, SUM(count), SUM(sum), MIN(min), MAX(max)
+				FROM weather_rollups
+				WHERE station_id = $2 AND granularity = $3 AND bucket_start >= $4 AND bucket_start < $5 AND metric = ANY($6)
+				GROUP BY 1, 2`,
+				bucket.Seconds(), stationID, g.Name, innerFrom, innerTo, pq.Array(fields),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if err := add(rows); err != nil {
+				return nil, err
+			}
+			rawRanges = [][2]time.Time{{from, innerFrom}, {innerTo, to}}
+		}
+	}
+
+	for _, rng := range rawRanges {
+		if !rng[0].Before(rng[1]) {
+			continue
+		}
+		parts := make([]string, 0, len(fields))
+		for _, f := range fields {
+			parts = append(parts, fmt.Sprintf(
+				`SELECT floor(extract(epoch FROM observed_at) / $1)::bigint, '%s', COUNT(%s), SUM(%s), MIN(%s), MAX(%s)
+				FROM observations
+				WHERE station_id = $2 AND observed_at >= $3 AND observed_at < $4 AND %s IS NOT NULL AND %s_quality IN ('%s')
+				GROUP BY 1`,
+				f, f, f, f, f, f, f, strings.Join(trustedQualities, "', '"),
+			))
+		}
+		rows, err := db.Query(strings.Join(parts, "\nUNION ALL\n"), bucket.Seconds(), stationID, rng[0], rng[1])
+		if err != nil {
+			return nil, err
+		}
+		if err := add(rows); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// rollupFor returns the coarsest rollup granularity that evenly divides bucket.
+func rollupFor(bucket time.Duration) (rollupGranularity, bool) {
+	for i := len(rollupGranularities) - 1; i >= 0; i-- {
+		if bucket%rollupGranularities[i].Size == 0 {
+			return rollupGranularities[i], true
+		}
+	}
+	return rollupGranularity{}, false
+}
+
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	truncated := t.UTC().Truncate(d)
+	if truncated.Before(t) {
+		return truncated.Add(d)
+	}
+	return truncated
+}
+
+// parseBucket parses a Go duration, additionally accepting a "d" suffix for days.
+func parseBucket(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d < time.Minute || d%time.Second != 0 {
+		return 0, fmt.Errorf("Invalid bucket %q, expected a duration of at least 1m such as 15m, 1h or 1d", s)
+	}
+	return d, nil
+}
+
+// splitList splits a comma separated query value, using def when it is empty.
+func splitList(s, def string) []string {
+	if s == "" {
+		s = def
+	}
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// metricIndex returns the position of name in observationMetrics or -1.
+func metricIndex(name string) int {
+	for i, m := range observationMetrics {
+		if m.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// rollupNamed returns the granularity stored under name.
+func rollupNamed(name string) (rollupGranularity, bool) {
+	for _, g := range rollupGranularities {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return rollupGranularity{}, false
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+// Alert lifecycle states. An alert is pending while its rule's condition
+// holds for less than the rule's duration, firing once it has held long
+// enough, acknowledged once an operator has seen it and resolved when the
+// condition clears. Pending alerts whose condition clears are dropped.
+const (
+	alertPending      = "pending"
+	alertFiring       = "firing"
+	alertAcknowledged = "acknowledged"
+	alertResolved     = "resolved"
+)
+
+// activeAlertStates are the states in which an alert still tracks its condition.
+var activeAlertStates = []string{alertPending, alertFiring, alertAcknowledged}
+
+// AlertRule raises an alert when metric compares to Threshold using Operator
+// at a station for at least Duration. An empty StationID matches every station.
+type AlertRule struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	StationID string    `json:"station_id,omitempty"`
+	Metric    string    `json:"metric"`
+	Operator  string    `json:"operator"`
+	Threshold float64   `json:"threshold"`
+	Unit      string    `json:"unit"`
+	Duration  string    `json:"duration"`
+	Severity  string    `json:"severity"`
+	Message   string    `json:"message"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Alert is one occurrence of a rule's condition at a station.
+type Alert struct {
+	ID             int        `json:"id"`
+	RuleID         int        `json:"rule_id"`
+	RuleName       string     `json:"rule_name"`
+	StationID      string     `json:"station_id"`
+	State          string     `json:"state"`
+	Severity       string     `json:"severity"`
+	Message        string     `json:"message"`
+	Metric         string     `json:"metric"`
+	Value          float64    `json:"value"`
+	Unit           string     `json:"unit"`
+	StartedAt      time.Time  `json:"started_at"`
+	FiredAt        *time.Time `json:"fired_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+var alertOperators = map[string]func(value, threshold float64) bool{
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"=":  func(v, t float64) bool { return v == t },
+}
+
+var alertSeverities = map[string]bool{"info": true, "warning": true, "critical": true}
+
+var alertTables = []string{
+	`CREATE TABLE IF NOT EXISTS alert_rules (
+		id SERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		station_id TEXT REFERENCES stations (id) ON DELETE CASCADE,
+		metric TEXT NOT NULL,
+		operator TEXT NOT NULL,
+		threshold DOUBLE PRECISION NOT NULL,
+		duration_seconds INTEGER NOT NULL DEFAULT 0,
+		severity TEXT NOT NULL DEFAULT 'warning',
+		message TEXT NOT NULL DEFAULT '',
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS alerts (
+		id SERIAL PRIMARY KEY,
+		rule_id INTEGER NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+		station_id TEXT NOT NULL REFERENCES stations (id) ON DELETE CASCADE,
+		state TEXT NOT NULL,
+		value DOUBLE PRECISION NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		fired_at TIMESTAMP,
+		acknowledged_at TIMESTAMP,
+		resolved_at TIMESTAMP
+	)`,
+	// At most one alert per rule and station may be tracking its condition.
+	`CREATE UNIQUE INDEX IF NOT EXISTS alerts_active_idx ON alerts (rule_id, station_id)
+		WHERE state IN ('pending', 'firing', 'acknowledged')`,
+}
+
+// evaluateAlertRules advances the alerts of every enabled rule that applies to
+// the station and metrics of a newly inserted observation.
+func evaluateAlertRules(tx *sql.Tx, o *Observation) error {
+	rows, err := tx.Query(
+		`SELECT id, metric, operator, threshold, duration_seconds FROM alert_rules
+		WHERE enabled AND (station_id IS NULL OR station_id = $1)`,
+		o.StationID,
+	)
+	if err != nil {
+		return err
+	}
+	type rule struct {
+		id              int
+		metric          string
+		operator        string
+		threshold       float64
+		durationSeconds int
+	}
+	var rules []rule
+	for rows.Next() {
+		var r rule
+		if err := rows.Scan(&r.id, &r.metric, &r.operator, &r.threshold, &r.durationSeconds); err != nil {
+			rows.Close()
+			return err
+		}
+		rules = append(rules, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	measurements := o.measurements()
+	for _, r := range rules {
+		i := metricIndex(r.metric)
+		compare := alertOperators[r.operator]
+		if i < 0 || compare == nil {
+			continue
+		}
+		m := *measurements[i]
+		if m == nil || !trustedQuality(m.Quality) {
+			continue
+		}
+		duration := time.Duration(r.durationSeconds) * time.Second
+		if err := advanceAlert(tx, r.id, o.StationID, compare(m.Value, r.threshold), m.Value, duration, o.ObservedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceAlert moves the active alert of a rule at a station through its
+// lifecycle given whether the condition holds at observedAt.
+func advanceAlert(tx *sql.Tx, ruleID int, stationID string, holds bool, value float64, duration time.Duration, observedAt time.Time) error {
+	var id int
+	var state string
+	var startedAt time.Time
+	err := tx.QueryRow(
+		`SELECT id, state, started_at FROM alerts
+		WHERE rule_id = $1 AND station_id = $2 AND state = ANY($3)
+		FOR UPDATE`,
+		ruleID, stationID, pq.Array(activeAlertStates),
+	).Scan(&id, &state, &startedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	active := err == nil
+
+	switch {
+	case holds && !active:
+		state := alertPending
+		var firedAt *time.Time
+		if duration == 0 {
+			state, firedAt = alertFiring, &observedAt
+		}
+		_, err = tx.Exec(
+			`INSERT INTO alerts (rule_id, station_id, state, value, started_at, fired_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (rule_id, station_id) WHERE state IN ('pending', 'firing', 'acknowledged') DO NOTHING`,
+			ruleID, stationID, state, value, observedAt, firedAt,
+		)
+	case holds && state == alertPending && observedAt.Sub(startedAt) >= duration:
+		_, err = tx.Exec(`UPDATE alerts SET state = $1, value = $2, fired_at = $3 WHERE id = $4`, alertFiring, value, observedAt, id)
+	case holds:
+		_, err = tx.Exec(`UPDATE alerts SET value = $1 WHERE id = $2`, value, id)
+	case active && state == alertPending:
+		_, err = tx.Exec(`DELETE FROM alerts WHERE id = $1`, id)
+	case active:
+		_, err = tx.Exec(`UPDATE alerts SET state = $1, value = $2, resolved_at = $3 WHERE id = $4`, alertResolved, value, observedAt, id)
+	default:
+		err = nil
+	}
+	return err
+}
+
+// decodeAlertRule reads a rule from the request body, converting its
+// threshold to the metric's canonical unit.
+func decodeAlertRule(r *http.Request) (AlertRule, time.Duration, error) {
+	rule := AlertRule{Severity: "warning", Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		return rule, 0, fmt.Errorf("Invalid input")
+	}
+	if rule.Name == "" {
+		return rule, 0, fmt.Errorf("Rule name is required")
+	}
+	i := metricIndex(rule.Metric)
+	if i < 0 {
+		return rule, 0, fmt.Errorf("Unknown metric %q", rule.Metric)
+	}
+	if alertOperators[rule.Operator] == nil {
+		return rule, 0, fmt.Errorf("Unknown operator %q, expected one of <, <=, >, >=, =", rule.Operator)
+	}
+	if !alertSeverities[rule.Severity] {
+		return rule, 0, fmt.Errorf("Unknown severity %q, expected info, warning or critical", rule.Severity)
+	}
+
+	var duration time.Duration
+	if rule.Duration != "" {
+		var err error
+		if duration, err = time.ParseDuration(rule.Duration); err != nil || duration < 0 {
+			return rule, 0, fmt.Errorf("Invalid duration %q", rule.Duration)
+		}
+		// Durations are stored in whole seconds.
+		if duration%time.Second != 0 {
+			return rule, 0, fmt.Errorf("Duration %q is not a whole number of seconds", rule.Duration)
+		}
+	}
+
+	canonical := observationMetrics[i].Unit
+	if rule.Unit != "" && rule.Unit != canonical {
+		threshold, err := toCanonical(rule.Metric, rule.Unit, rule.Threshold)
+		if err != nil {
+			return rule, 0, err
+		}
+		rule.Threshold = threshold
+	}
+	rule.Unit = canonical
+	return rule, duration, nil
+}
+
+const alertRuleColumns = `id, name, COALESCE(station_id, ''), metric, operator, threshold, duration_seconds, severity, message, enabled, created_at`
+
+func scanAlertRule(row rowScanner) (AlertRule, error) {
+	var rule AlertRule
+	var durationSeconds int
+	err := row.Scan(&rule.ID, &rule.Name, &rule.StationID, &rule.Metric, &rule.Operator, &rule.Threshold,
+		&durationSeconds, &rule.Severity, &rule.Message, &rule.Enabled, &rule.CreatedAt)
+	rule.Duration = (time.Duration(durationSeconds) * time.Second).String()
+	if i := metricIndex(rule.Metric); i >= 0 {
+		rule.Unit = observationMetrics[i].Unit
+	}
+	return rule, err
+}
+
+// alertRuleStationExists reports whether the station a rule is limited to
+// exists. A rule for every station has none to check.
+func alertRuleStationExists(ctx context.Context, stationID string) (bool, error) {
+	if stationID == "" {
+		return true, nil
+	}
+	var exists bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM stations WHERE id = $1)`, stationID).Scan(&exists)
+	return exists, err
+}
+
+func addAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, duration, err := decodeAlertRule(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exists, err := alertRuleStationExists(r.Context(), rule.StationID)
+	if err != nil {
+		http.Error(w, "Failed to add alert rule", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	}
+
+	var id int
+	err = db.QueryRowContext(r.Context(),
+		`INSERT INTO alert_rules (name, station_id, metric, operator, threshold, duration_seconds, severity, message, enabled)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		rule.Name, rule.StationID, rule.Metric, rule.Operator, rule.Threshold, int(duration.Seconds()), rule.Severity, rule.Message, rule.Enabled,
+	).Scan(&id)
+	if err != nil {
+		http.Error(w, "Failed to add alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "Alert rule added with ID: %d", id)
+}
+
+func getAlertRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	rule, err := scanAlertRule(db.QueryRowContext(r.Context(), `SELECT `+alertRuleColumns+` FROM alert_rules WHERE id = $1`, id))
+	if err != nil {
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func updateAlertRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	rule, duration, err := decodeAlertRule(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exists, err := alertRuleStationExists(r.Context(), rule.StationID)
+	if err != nil {
+		http.Error(w, "Failed to update alert rule", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	}
+
+	result, err := db.ExecContext(r.Context(),
+		`UPDATE alert_rules SET name = $1, station_id = NULLIF($2, ''), metric = $3, operator = $4, threshold = $5,
+			duration_seconds = $6, severity = $7, message = $8, enabled = $9
+		WHERE id = $10`,
+		rule.Name, rule.StationID, rule.Metric, rule.Operator, rule.Threshold, int(duration.Seconds()), rule.Severity, rule.Message, rule.Enabled, id,
+	)
+	if err != nil {
+		http.Error(w, "Failed to update alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Alert rule %s updated", id)
+}
+
+func deleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	_, err := db.ExecContext(r.Context(), `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		http.Error(w, "Failed to delete alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("Alert rule deleted"))
+}
+
+func listAlertRules(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.QueryContext(r.Context(), `SELECT `+alertRuleColumns+` FROM alert_rules ORDER BY id`)
+	if err != nil {
+		http.Error(w, "Failed to query alert rules", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	rules := []AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			http.Error(w, "Failed to read alert rules", http.StatusInternalServerError)
+			return
+		}
+		rules = append(rules, rule)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+const alertColumns = `a.id, a.rule_id, r.name, a.station_id, a.state, r.severity, r.message, r.metric, a.value,
+	a.started_at, a.fired_at, a.acknowledged_at, a.resolved_at`
+
+func scanAlert(row rowScanner, units string) (Alert, error) {
+	var a Alert
+	err := row.Scan(&a.ID, &a.RuleID, &a.RuleName, &a.StationID, &a.State, &a.Severity, &a.Message, &a.Metric, &a.Value,
+		&a.StartedAt, &a.FiredAt, &a.AcknowledgedAt, &a.ResolvedAt)
+	if err == nil && metricIndex(a.Metric) >= 0 {
+		c := conversion(units, a.Metric)
+		a.Value, a.Unit = c.from(a.Value), c.Unit
+	}
+	return a, err
+}
+
+// listAlerts returns alerts in the states given by the comma separated state
+// query parameter, by default the ones that are firing or acknowledged.
+func listAlerts(w http.ResponseWriter, r *http.Request) {
+	states := splitList(r.URL.Query().Get("state"), alertFiring+","+alertAcknowledged)
+	for _, s := range states {
+		switch s {
+		case alertPending, alertFiring, alertAcknowledged, alertResolved:
+		default:
+			http.Error(w, fmt.Sprintf("Unknown state %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+
+	rows, err := db.QueryContext(r.Context(),
+		`SELECT `+alertColumns+` FROM alerts a JOIN alert_rules r ON r.id = a.rule_id
+		WHERE a.state = ANY($1)
+		ORDER BY a.started_at DESC LIMIT 500`,
+		pq.Array(states),
+	)
+	if err != nil {
+		http.Error(w, "Failed to query alerts", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	units := requestUnits(r)
+	alerts := []Alert{}
+	for rows.Next() {
+		a, err := scanAlert(rows, units)
+		if err != nil {
+			http.Error(w, "Failed to read alerts", http.StatusInternalServerError)
+			return
+		}
+		alerts = append(alerts, a)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts)
+}
+
+func getAlert(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	a, err := scanAlert(db.QueryRowContext(r.Context(), `SELECT `+alertColumns+` FROM alerts a JOIN alert_rules r ON r.id = a.rule_id WHERE a.id = $1`, id), requestUnits(r))
+	if err != nil {
+		http.Error(w, "Alert not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+func acknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	transitionAlert(w, r, alertAcknowledged, "acknowledged_at", []string{alertFiring})
+}
+
+func resolveAlert(w http.ResponseWriter, r *http.Request) {
+	transitionAlert(w, r, alertResolved, "resolved_at", []string{alertFiring, alertAcknowledged})
+}
+
+// transitionAlert moves an alert into state, stamping column, provided it is
+// currently in one of the from states.
+func transitionAlert(w http.ResponseWriter, r *http.Request, state, column string, from []string) {
+	id := chi.URLParam(r, "id")
+	result, err := db.ExecContext(r.Context(),
+		`UPDATE alerts SET state = $1, `+column+` = CURRENT_TIMESTAMP WHERE id = $2 AND state = ANY($3)`,
+		state, id, pq.Array(from),
+	)
+	if err != nil {
+		http.Error(w, "Failed to update alert", http.StatusInternalServerError)
+		return
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		http.Error(w, fmt.Sprintf("No alert %s in state %s", id, strings.Join(from, " or ")), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Alert %s %s", id, state)
+}
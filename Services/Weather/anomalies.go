@@ -0,0 +1,426 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+// Anomaly reason codes.
+const (
+	anomalySpike    = "spike"    // value far outside the station's recent distribution
+	anomalyFlatline = "flatline" // value stuck at the same number for too many readings
+	anomalyGap      = "gap"      // station stopped reporting for longer than expected
+)
+
+// Review states of an anomaly.
+const (
+	anomalyPending  = "pending"
+	anomalyAccepted = "accepted"
+	anomalyRejected = "rejected"
+)
+
+const (
+	anomalyWindow     = 30 // readings the rolling statistics look back over
+	anomalyMinSamples = 10 // readings needed before a z-score is trusted
+	anomalyZScore     = 4.0
+	flatlineRun       = 12 // identical consecutive readings, including the new one
+	anomalyShiftRun   = 5  // agreeing spikes taken as a change of level, such as a moved sensor
+	anomalyGapAfter   = 2 * time.Hour
+	anomalyHistory    = 24 * time.Hour
+)
+
+// anomalyCheck configures detection per metric. MinStdDev keeps a very steady
+// series from turning ordinary changes into huge z-scores.
+type anomalyCheck struct {
+	ZScore    bool
+	MinStdDev float64
+	Flatline  bool
+}
+
+// anomalyChecks holds the checks per metric. Wind direction is circular and
+// precipitation is mostly zero, so neither gets a z-score; precipitation and
+// visibility legitimately stay constant for hours.
+var anomalyChecks = map[string]anomalyCheck{
+	"temperature":    {ZScore: true, MinStdDev: 0.5, Flatline: true},
+	"humidity":       {ZScore: true, MinStdDev: 2, Flatline: true},
+	"pressure":       {ZScore: true, MinStdDev: 0.5, Flatline: true},
+	"wind_speed":     {ZScore: true, MinStdDev: 2, Flatline: false},
+	"wind_direction": {ZScore: false, Flatline: false},
+	"precipitation":  {ZScore: false, Flatline: false},
+	"visibility":     {ZScore: true, MinStdDev: 1, Flatline: false},
+}
+
+const anomaliesTable = `CREATE TABLE IF NOT EXISTS weather_anomalies (
+	id SERIAL PRIMARY KEY,
+	observation_id INTEGER NOT NULL REFERENCES observations (id) ON DELETE CASCADE,
+	station_id TEXT NOT NULL REFERENCES stations (id) ON DELETE CASCADE,
+	metric TEXT,
+	reason TEXT NOT NULL,
+	value DOUBLE PRECISION,
+	original_quality TEXT,
+	detail TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'pending',
+	detected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	reviewed_at TIMESTAMP
+)`
+
+// Anomaly is a reading, or a gap before it, waiting for or past review.
+type Anomaly struct {
+	ID              int        `json:"id"`
+	ObservationID   int        `json:"observation_id"`
+	StationID       string     `json:"station_id"`
+	Metric          string     `json:"metric,omitempty"`
+	Reason          string     `json:"reason"`
+	Value           *float64   `json:"value,omitempty"`
+	OriginalQuality string     `json:"original_quality,omitempty"`
+	Detail          string     `json:"detail"`
+	Status          string     `json:"status"`
+	DetectedAt      time.Time  `json:"detected_at"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// detectAnomalies compares o with the station's recent trusted readings.
+// Metrics found to be spikes or flatlines are quarantined by marking them
+// suspect, which keeps them out of rollups and alert rules until reviewed.
+// A spike that agrees with the run of spikes before it re-baselines the
+// metric instead: the run is accepted and o is trusted. The observations the
+// run restored are returned for the caller to publish.
+func detectAnomalies(tx *sql.Tx, o *Observation) (anomalies []Anomaly, restored []int, err error) {
+	observedAt := o.ObservedAt
+	if observedAt.IsZero() {
+		observedAt = time.Now().UTC()
+	}
+
+	rows, err := tx.Query(
+		`SELECT `+observationColumns()+` FROM observations
+		WHERE station_id = $1 AND observed_at < $2 AND observed_at >= $3
+		ORDER BY observed_at DESC LIMIT $4`,
+		o.StationID, observedAt, observedAt.Add(-anomalyHistory), anomalyWindow,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var history []Observation
+	for rows.Next() {
+		h, err := scanObservation(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		history = append(history, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(history) == 0 {
+		return nil, nil, nil
+	}
+
+	if gap := observedAt.Sub(history[0].ObservedAt); gap > anomalyGapAfter {
+		hours := gap.Hours()
+		anomalies = append(anomalies, Anomaly{
+			Reason: anomalyGap,
+			Value:  &hours,
+			Detail: fmt.Sprintf("no readings for %s before this one", gap.Round(time.Minute)),
+		})
+	}
+
+	for i, m := range o.measurements() {
+		if *m == nil || !trustedQuality((*m).Quality) {
+			continue
+		}
+		metric := observationMetrics[i].Name
+		check := anomalyChecks[metric]
+
+		var recent []float64
+		// run holds the suspect readings since the last trusted one, newest
+		// first, and runIDs their observations.
+		var run []float64
+		var runIDs []int
+		inRun := true
+		for _, h := range history {
+			hm := *h.measurements()[i]
+			if hm == nil {
+				continue
+			}
+			if trustedQuality(hm.Quality) {
+				recent = append(recent, hm.Value)
+			}
+			inRun = inRun && hm.Quality == qualitySuspect
+			if inRun && len(run) < anomalyShiftRun {
+				run = append(run, hm.Value)
+				runIDs = append(runIDs, h.ID)
+			}
+		}
+
+		value := (*m).Value
+		reason, detail := "", ""
+		if check.Flatline && len(recent) >= flatlineRun-1 {
+			stuck := true
+			for _, v := range recent[:flatlineRun-1] {
+				stuck = stuck && v == value
+			}
+			if stuck {
+				reason, detail = anomalyFlatline, fmt.Sprintf("%d consecutive readings of %g", flatlineRun, value)
+			}
+		}
+		if reason == "" && check.ZScore && len(recent) >= anomalyMinSamples {
+			mu := mean(recent)
+			variance := 0.0
+			for _, v := range recent {
+				variance += (v - mu) * (v - mu)
+			}
+			std := math.Max(math.Sqrt(variance/float64(len(recent)-1)), check.MinStdDev)
+			if z := (value - mu) / std; math.Abs(z) > anomalyZScore {
+				reason, detail = anomalySpike, fmt.Sprintf("z-score %.1f against mean %.2f and std dev %.2f of %d readings", z, mu, std, len(recent))
+			}
+			if reason == anomalySpike && levelShifted(value, mu, run, check.MinStdDev) {
+				ids, err := acceptShiftedReadings(tx, metric, runIDs)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, id := range ids {
+					if !slices.Contains(restored, id) {
+						restored = append(restored, id)
+					}
+				}
+				reason = ""
+			}
+		}
+		if reason == "" {
+			continue
+		}
+
+		anomalies = append(anomalies, Anomaly{
+			Metric:          metric,
+			Reason:          reason,
+			Value:           &value,
+			OriginalQuality: (*m).Quality,
+			Detail:          detail,
+		})
+		(*m).Quality = qualitySuspect
+	}
+	return anomalies, restored, nil
+}
+
+// levelShifted reports whether value and a full run of suspect readings sit
+// on the same side of the trusted mean and agree with each other, the run's
+// spread being no wider than a z-score of anomalyZScore allows.
+func levelShifted(value, trustedMean float64, run []float64, minStdDev float64) bool {
+	if len(run) < anomalyShiftRun {
+		return false
+	}
+	above := value > trustedMean
+	for _, v := range run {
+		if (v > trustedMean) != above {
+			return false
+		}
+	}
+	mu := mean(run)
+	variance := 0.0
+	for _, v := range run {
+		variance += (v - mu) * (v - mu)
+	}
+	std := math.Max(math.Sqrt(variance/float64(len(run)-1)), minStdDev)
+	return math.Abs(value-mu)/std <= anomalyZScore
+}
+
+// acceptShiftedReadings accepts the pending anomalies of metric on the given
+// observations, as a review would, so the readings count again and become
+// the baseline new readings are compared with. It returns the observations
+// it restored.
+func acceptShiftedReadings(tx *sql.Tx, metric string, observationIDs []int) ([]int, error) {
+	rows, err := tx.Query(
+		`UPDATE weather_anomalies SET status = $1, reviewed_at = CURRENT_TIMESTAMP
+		WHERE observation_id = ANY($2) AND metric = $3 AND status = $4
+		RETURNING observation_id, COALESCE(original_quality, '')`,
+		anomalyAccepted, pq.Array(observationIDs), metric, anomalyPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+	restored := map[int]string{}
+	for rows.Next() {
+		var id int
+		var quality string
+		if err := rows.Scan(&id, &quality); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		restored[id] = quality
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(restored))
+	for id, quality := range restored {
+		var stationID string
+		var observedAt time.Time
+		if err := tx.QueryRow(
+			`UPDATE observations SET `+metric+`_quality = $1 WHERE id = $2 RETURNING station_id, observed_at`,
+			quality, id,
+		).Scan(&stationID, &observedAt); err != nil {
+			return nil, err
+		}
+		if err := refreshRollups(tx, stationID, observedAt); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// recordAnomalies queues anomalies detected for a stored observation for review.
+func recordAnomalies(tx *sql.Tx, o *Observation, anomalies []Anomaly) error {
+	for _, a := range anomalies {
+		if _, err := tx.Exec(
+			`INSERT INTO weather_anomalies (observation_id, station_id, metric, reason, value, original_quality, detail)
+			VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7)`,
+			o.ID, o.StationID, a.Metric, a.Reason, a.Value, a.OriginalQuality, a.Detail,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const anomalyColumns = `id, observation_id, station_id, COALESCE(metric, ''), reason, value, COALESCE(original_quality, ''), detail, status, detected_at, reviewed_at`
+
+func scanAnomaly(row rowScanner) (Anomaly, error) {
+	var a Anomaly
+	err := row.Scan(&a.ID, &a.ObservationID, &a.StationID, &a.Metric, &a.Reason, &a.Value,
+		&a.OriginalQuality, &a.Detail, &a.Status, &a.DetectedAt, &a.ReviewedAt)
+	return a, err
+}
+
+// listAnomalies serves the review queue. It returns pending anomalies unless
+// status asks for accepted or rejected ones, optionally for one station.
+func listAnomalies(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = anomalyPending
+	}
+	if status != anomalyPending && status != anomalyAccepted && status != anomalyRejected {
+		http.Error(w, fmt.Sprintf("Unknown status %q", status), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.QueryContext(r.Context(),
+		`SELECT `+anomalyColumns+` FROM weather_anomalies
+		WHERE status = $1 AND ($2 = '' OR station_id = $2)
+		ORDER BY detected_at DESC LIMIT 500`,
+		status, r.URL.Query().Get("station"),
+	)
+	if err != nil {
+		http.Error(w, "Failed to query anomalies", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	anomalies := []Anomaly{}
+	for rows.Next() {
+		a, err := scanAnomaly(rows)
+		if err != nil {
+			http.Error(w, "Failed to read anomalies", http.StatusInternalServerError)
+			return
+		}
+		anomalies = append(anomalies, a)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(anomalies)
+}
+
+func getAnomaly(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	a, err := scanAnomaly(db.QueryRowContext(r.Context(), `SELECT `+anomalyColumns+` FROM weather_anomalies WHERE id = $1`, id))
+	if err != nil {
+		http.Error(w, "Anomaly not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// acceptAnomaly restores the reading's original quality so it counts again.
+func acceptAnomaly(w http.ResponseWriter, r *http.Request) {
+	reviewAnomaly(w, r, anomalyAccepted)
+}
+
+// rejectAnomaly marks the reading bad for good.
+func rejectAnomaly(w http.ResponseWriter, r *http.Request) {
+	reviewAnomaly(w, r, anomalyRejected)
+}
+
+func reviewAnomaly(w http.ResponseWriter, r *http.Request, status string) {
+	id := chi.URLParam(r, "id")
+
+	tx, err := db.BeginTx(r.Context(), nil)
+	if err != nil {
+		http.Error(w, "Failed to review anomaly", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var observationID int
+	var stationID, metric, originalQuality string
+	err = tx.QueryRowContext(r.Context(),
+		`UPDATE weather_anomalies SET status = $1, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND status = $3
+		RETURNING observation_id, station_id, COALESCE(metric, ''), COALESCE(original_quality, '')`,
+		status, id, anomalyPending,
+	).Scan(&observationID, &stationID, &metric, &originalQuality)
+	if err == sql.ErrNoRows {
+		http.Error(w, "No pending anomaly with that ID", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to review anomaly", http.StatusInternalServerError)
+		return
+	}
+
+	// Gaps carry no reading; reviewing one only records that it was seen.
+	if metricIndex(metric) >= 0 {
+		quality := qualityBad
+		if status == anomalyAccepted {
+			quality = originalQuality
+		}
+		var observedAt time.Time
+		err := tx.QueryRowContext(r.Context(),
+			`UPDATE observations SET `+metric+`_quality = $1 WHERE id = $2 RETURNING observed_at`,
+			quality, observationID,
+		).Scan(&observedAt)
+		if err != nil {
+			http.Error(w, "Failed to review anomaly", http.StatusInternalServerError)
+			return
+		}
+		if err := refreshRollups(tx, stationID, observedAt); err != nil {
+			http.Error(w, "Failed to review anomaly", http.StatusInternalServerError)
+			return
+		}
+		if err := publishObservationUpdate(tx, observationID); err != nil {
+			http.Error(w, "Failed to review anomaly", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Failed to review anomaly", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Anomaly %s %s", id, status)
+}
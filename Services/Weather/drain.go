@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// draining is set once the instance has been asked to stop. From then on
+// /readyz fails, so nothing that polls it sends more requests.
+var draining atomic.Bool
+
+// drainConfig holds how long shutdown waits for Traefik to stop routing to the
+// instance (DRAIN_DELAY) and then for in-flight requests to finish
+// (DRAIN_TIMEOUT). Traefik polls Consul every couple of seconds, so the delay
+// must cover at least one poll.
+type drainConfig struct {
+	delay   time.Duration
+	timeout time.Duration
+}
+
+func loadDrainConfig() drainConfig {
+	return drainConfig{
+		delay:   envDuration("DRAIN_DELAY", 5*time.Second),
+		timeout: envDuration("DRAIN_TIMEOUT", 20*time.Second),
+	}
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		slog.Warn("Ignoring invalid duration", "variable", name, "value", value, "default", fallback.String())
+		return fallback
+	}
+	return d
+}
+
+// drain takes the instance out of rotation before closing the servers: it
+// fails readiness and gRPC health, deregisters, waits for Traefik to drop the
+// instance and only then shuts the servers down, letting in-flight requests
+// and calls finish within the timeout. Shutting the HTTP server down closes
+// the event streams, which ends the gRPC Watch calls too.
+func drain(server *http.Server, grpcServer *grpc.Server, config drainConfig) {
+	draining.Store(true)
+	grpcHealth.Shutdown()
+	deregister()
+	// Responses sent from now on close their connection, so Traefik does not
+	// reuse one the shutdown is about to close.
+	server.SetKeepAlivesEnabled(false)
+
+	slog.Info("Draining", "delay", config.delay.String(), "timeout", config.timeout.String())
+	time.Sleep(config.delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.timeout)
+	defer cancel()
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("HTTP server Shutdown", "error", err)
+	}
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		slog.Error("gRPC server GracefulStop", "error", ctx.Err())
+		grpcServer.Stop()
+	}
+}
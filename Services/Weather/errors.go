@@ -0,0 +1,17 @@
+package main
+
+import "errors"
+
+// Errors returned by the storage functions the REST and gRPC APIs share.
+var (
+	errNotFound = errors.New("not found")
+	errConflict = errors.New("already exists")
+)
+
+// invalidInputError is input rejected by validation. Its message is sent to
+// the client: with 400 over REST and InvalidArgument over gRPC.
+type invalidInputError string
+
+func (e invalidInputError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	forecastSeason    = 24 // hourly data has a daily season
+	forecastHistory   = 14 * 24 * time.Hour
+	forecastMaxHours  = 6 // nowcasting horizon cap
+	forecastMinPoints = 6 // below this not even a trend can be fitted
+	backtestOrigins   = 5
+)
+
+// confidenceZ maps the supported interval levels to normal quantiles.
+var confidenceZ = map[int]float64{80: 1.2816, 90: 1.6449, 95: 1.9600, 99: 2.5758}
+
+// smoothingState is what exponential smoothing has learned after the last
+// point: level, trend and, for Holt-Winters, one seasonal term per hour of day.
+type smoothingState struct {
+	level    float64
+	trend    float64
+	seasonal []float64
+	n        int
+}
+
+// smoothingParams are the smoothing factors of level, trend and season.
+type smoothingParams struct {
+	Alpha float64 `json:"alpha"`
+	Beta  float64 `json:"beta"`
+	Gamma float64 `json:"gamma,omitempty"`
+}
+
+// smooth runs additive Holt-Winters over y with season length m, or Holt's
+// linear method when m is 0. It returns the final state and the sum of
+// squared one-step-ahead errors over the n points it could predict.
+func smooth(y []float64, m int, p smoothingParams) (state smoothingState, sse float64, n int) {
+	start := 1
+	state.level, state.trend = y[0], y[1]-y[0]
+	if m > 0 {
+		first, second := mean(y[:m]), mean(y[m:2*m])
+		state.level, state.trend = first, (second-first)/float64(m)
+		state.seasonal = make([]float64, m)
+		for i := 0; i < m; i++ {
+			state.seasonal[i] = y[i] - first
+		}
+		start = m
+		// The level initialised from the first season is centred within it.
+		state.level += state.trend * float64(m-1) / 2
+	}
+
+	for t := start; t < len(y); t++ {
+		season := 0.0
+		if m > 0 {
+			season = state.seasonal[t%m]
+		}
+		e := y[t] - (state.level + state.trend + season)
+		sse += e * e
+		n++
+
+		level := p.Alpha*(y[t]-season) + (1-p.Alpha)*(state.level+state.trend)
+		state.trend = p.Beta*(level-state.level) + (1-p.Beta)*state.trend
+		state.level = level
+		if m > 0 {
+			state.seasonal[t%m] = p.Gamma*(y[t]-level) + (1-p.Gamma)*season
+		}
+	}
+	state.n = len(y)
+	return state, sse, n
+}
+
+// predict returns the forecast h steps after the last smoothed point.
+func (s smoothingState) predict(h int) float64 {
+	v := s.level + float64(h)*s.trend
+	if len(s.seasonal) > 0 {
+		v += s.seasonal[(s.n-1+h)%len(s.seasonal)]
+	}
+	return v
+}
+
+// fittedModel is a smoothing model whose parameters minimise the one-step
+// error on the history it was fitted to.
+type fittedModel struct {
+	name   string
+	season int
+	params smoothingParams
+	state  smoothingState
+	sigma  float64 // standard deviation of one-step-ahead errors
+}
+
+// fitForecastModel picks Holt-Winters when there are at least two full
+// seasons of history and Holt's linear method otherwise, and grid searches
+// the smoothing factors.
+func fitForecastModel(y []float64) (fittedModel, error) {
+	if len(y) < forecastMinPoints {
+		return fittedModel{}, fmt.Errorf("at least %d hours of history are needed, have %d", forecastMinPoints, len(y))
+	}
+	model := fittedModel{name: "holt", season: 0}
+	if len(y) >= 2*forecastSeason {
+		model = fittedModel{name: "holt-winters", season: forecastSeason}
+	}
+
+	grid := []float64{0.05, 0.1, 0.2, 0.3, 0.5, 0.7, 0.9}
+	gammas := []float64{0}
+	if model.season > 0 {
+		gammas = grid
+	}
+	best := math.Inf(1)
+	for _, a := range grid {
+		for _, b := range grid {
+			for _, g := range gammas {
+				p := smoothingParams{Alpha: a, Beta: b, Gamma: g}
+				state, sse, n := smooth(y, model.season, p)
+				if n > 0 && sse < best {
+					best = sse
+					model.params, model.state = p, state
+					model.sigma = math.Sqrt(sse / float64(n))
+				}
+			}
+		}
+	}
+	return model, nil
+}
+
+// intervalWidth returns the half width of the prediction interval h steps
+// ahead, widening the one-step error as Hyndman et al. derive for additive
+// Holt-Winters.
+func (m fittedModel) intervalWidth(h int, z float64) float64 {
+	variance := 1.0
+	for j := 1; j < h; j++ {
+		c := m.params.Alpha * (1 + float64(j)*m.params.Beta)
+		if m.season > 0 && j%m.season == 0 {
+			c += m.params.Gamma * (1 - m.params.Alpha)
+		}
+		variance += c * c
+	}
+	return z * m.sigma * math.Sqrt(variance)
+}
+
+// BacktestMetrics reports how well the model predicted held-out history.
+type BacktestMetrics struct {
+	Origins  int      `json:"origins"`
+	Horizon  int      `json:"horizon"`
+	MAE      float64  `json:"mae"`
+	RMSE     float64  `json:"rmse"`
+	MAPE     *float64 `json:"mape,omitempty"`
+	Coverage float64  `json:"coverage"`
+}
+
+// backtest refits the model at up to backtestOrigins points in the past and
+// compares its forecasts with what was observed in the following hours.
+func backtest(y []float64, horizon int, z float64) *BacktestMetrics {
+	var absSum, sqSum, pctSum float64
+	var count, pctCount, covered, origins int
+	for k := 1; k <= backtestOrigins; k++ {
+		cut := len(y) - k*horizon
+		if cut < forecastMinPoints {
+			break
+		}
+		model, err := fitForecastModel(y[:cut])
+		if err != nil {
+			break
+		}
+		origins++
+		for h := 1; h <= horizon; h++ {
+			predicted, actual := model.state.predict(h), y[cut+h-1]
+			e := actual - predicted
+			absSum += math.Abs(e)
+			sqSum += e * e
+			count++
+			if actual != 0 {
+				pctSum += math.Abs(e / actual)
+				pctCount++
+			}
+			if math.Abs(e) <= model.intervalWidth(h, z) {
+				covered++
+			}
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	metrics := &BacktestMetrics{
+		Origins:  origins,
+		Horizon:  horizon,
+		MAE:      round2(absSum / float64(count)),
+		RMSE:     round2(math.Sqrt(sqSum / float64(count))),
+		Coverage: round2(float64(covered) / float64(count)),
+	}
+	if pctCount > 0 {
+		mape := round2(100 * pctSum / float64(pctCount))
+		metrics.MAPE = &mape
+	}
+	return metrics
+}
+
+// hourlySeries loads hourly averages of a metric for a station from the 1h
+// rollups, linearly interpolating hours without data. It returns the values
+// and the start of the first hour.
+func hourlySeries(stationID, metric string, from, to time.Time) ([]float64, time.Time, error) {
+	rows, err := db.Query(
+		`SELECT bucket_start, sum / count FROM weather_rollups
+		WHERE station_id = $1 AND metric = $2 AND granularity = '1h' AND bucket_start >= $3 AND bucket_start < $4
+		ORDER BY bucket_start`,
+		stationID, metric, from, to,
+	)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	var values []float64
+	var first, prev time.Time
+	for rows.Next() {
+		var bucket time.Time
+		var v float64
+		if err := rows.Scan(&bucket, &v); err != nil {
+			return nil, time.Time{}, err
+		}
+		bucket = bucket.UTC()
+		if values == nil {
+			first = bucket
+		} else if gap := int(bucket.Sub(prev) / time.Hour); gap > 1 {
+			last := values[len(values)-1]
+			for i := 1; i < gap; i++ {
+				values = append(values, last+(v-last)*float64(i)/float64(gap))
+			}
+		}
+		values = append(values, v)
+		prev = bucket
+	}
+	return values, first, rows.Err()
+}
+
+// ForecastPoint is a predicted value with its prediction interval.
+type ForecastPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Lower float64   `json:"lower"`
+	Upper float64   `json:"upper"`
+}
+
+// SeriesPoint is an observed hourly average.
+type SeriesPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// WeatherForecast is the response of GET /weather/forecast.
+type WeatherForecast struct {
+	StationID     string           `json:"station_id"`
+	Metric        string           `json:"metric"`
+	Unit          string           `json:"unit"`
+	Model         string           `json:"model"`
+	Params        smoothingParams  `json:"params"`
+	Level         int              `json:"level"`
+	HistoryPoints int              `json:"history_points"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+	Observed      []SeriesPoint    `json:"observed"`
+	Forecast      []ForecastPoint  `json:"forecast"`
+	Backtest      *BacktestMetrics `json:"backtest,omitempty"`
+}
+
+// forecastWeather serves GET /weather/forecast?location=&metric=&hours=&level=.
+// It fits a model to the last two weeks of hourly averages and predicts the
+// next 1-6 hours, returning the last day of observations alongside.
+func forecastWeather(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	location := query.Get("location")
+	if location == "" {
+		http.Error(w, "location query parameter is required", http.StatusBadRequest)
+		return
+	}
+	stationID, err := resolveStation(location)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to resolve station", http.StatusInternalServerError)
+		return
+	}
+
+	metric := query.Get("metric")
+	if metric == "" {
+		metric = "temperature"
+	}
+	if metricIndex(metric) < 0 {
+		http.Error(w, fmt.Sprintf("Unknown metric %q", metric), http.StatusBadRequest)
+		return
+	}
+	hours := forecastMaxHours
+	if s := query.Get("hours"); s != "" {
+		if hours, err = strconv.Atoi(s); err != nil || hours < 1 || hours > forecastMaxHours {
+			http.Error(w, fmt.Sprintf("hours must be between 1 and %d", forecastMaxHours), http.StatusBadRequest)
+			return
+		}
+	}
+	level := 95
+	if s := query.Get("level"); s != "" {
+		level, _ = strconv.Atoi(s)
+	}
+	z, ok := confidenceZ[level]
+	if !ok {
+		http.Error(w, "level must be one of 80, 90, 95 or 99", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	values, first, err := hourlySeries(stationID, metric, now.Add(-forecastHistory), now)
+	if err != nil {
+		http.Error(w, "Failed to load weather history", http.StatusInternalServerError)
+		return
+	}
+	model, err := fitForecastModel(values)
+	if err != nil {
+		http.Error(w, "Not enough history to forecast: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	c := conversion(requestUnits(r), metric)
+	last := first.Add(time.Duration(len(values)-1) * time.Hour)
+	forecast := make([]ForecastPoint, 0, hours)
+	for h := 1; h <= hours; h++ {
+		v, width := model.state.predict(h), model.intervalWidth(h, z)
+		forecast = append(forecast, ForecastPoint{
+			Time:  last.Add(time.Duration(h) * time.Hour),
+			Value: c.from(v),
+			Lower: c.from(v - width),
+			Upper: c.from(v + width),
+		})
+	}
+	observed := []SeriesPoint{}
+	for i := max(0, len(values)-forecastSeason); i < len(values); i++ {
+		observed = append(observed, SeriesPoint{Time: first.Add(time.Duration(i) * time.Hour), Value: c.from(values[i])})
+	}
+
+	backtestMetrics := backtest(values, hours, z)
+	if backtestMetrics != nil {
+		// Absolute errors only scale between unit systems.
+		backtestMetrics.MAE = round2(backtestMetrics.MAE * c.Scale)
+		backtestMetrics.RMSE = round2(backtestMetrics.RMSE * c.Scale)
+	}
+
+	response := WeatherForecast{stationID, metric, c.Unit, model.name, model.params, level, len(values), now, observed, forecast, backtestMetrics}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func mean(v []float64) float64 {
+	sum := 0.0
+	for _, x := range v {
+		sum += x
+	}
+	return sum / float64(len(v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"metagrid/weather/weatherpb"
+)
+
+// weatherServer implements the gRPC API over the same storage functions as
+// the REST handlers.
+type weatherServer struct {
+	weatherpb.UnimplementedWeatherServer
+}
+
+var (
+	unitsFromProto = map[weatherpb.Units]string{
+		weatherpb.Units_UNITS_UNSPECIFIED: unitsMetric,
+		weatherpb.Units_UNITS_METRIC:      unitsMetric,
+		weatherpb.Units_UNITS_IMPERIAL:    unitsImperial,
+		weatherpb.Units_UNITS_SI:          unitsSI,
+	}
+	qualitiesToProto = map[string]weatherpb.Quality{
+		qualityGood:      weatherpb.Quality_QUALITY_GOOD,
+		qualityEstimated: weatherpb.Quality_QUALITY_ESTIMATED,
+		qualitySuspect:   weatherpb.Quality_QUALITY_SUSPECT,
+		qualityBad:       weatherpb.Quality_QUALITY_BAD,
+	}
+	actionsToProto = map[string]weatherpb.Action{
+		"created": weatherpb.Action_ACTION_CREATED,
+		"updated": weatherpb.Action_ACTION_UPDATED,
+		"deleted": weatherpb.Action_ACTION_DELETED,
+	}
+)
+
+// units returns the unit system a request asks for. Values newer than this
+// build are rejected rather than silently served in metric.
+func units(u weatherpb.Units) (string, error) {
+	system, ok := unitsFromProto[u]
+	if !ok {
+		return "", invalidInputError(fmt.Sprintf("unknown units %d", u))
+	}
+	return system, nil
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func stationToProto(s Station) *weatherpb.Station {
+	return &weatherpb.Station{
+		Id:        s.ID,
+		Name:      s.Name,
+		Latitude:  s.Latitude,
+		Longitude: s.Longitude,
+		CreatedAt: timestamp(s.CreatedAt),
+	}
+}
+
+func observationToProto(o Observation) *weatherpb.Observation {
+	msg := &weatherpb.Observation{
+		Id:          int32(o.ID),
+		StationId:   o.StationID,
+		ObservedAt:  timestamp(o.ObservedAt),
+		Description: o.Description,
+	}
+	fields := []**weatherpb.Measurement{
+		&msg.Temperature,
+		&msg.Humidity,
+		&msg.Pressure,
+		&msg.WindSpeed,
+		&msg.WindDirection,
+		&msg.Precipitation,
+		&msg.Visibility,
+	}
+	for i, m := range o.measurements() {
+		if *m != nil {
+			*fields[i] = &weatherpb.Measurement{Value: (*m).Value, Unit: (*m).Unit, Quality: qualitiesToProto[(*m).Quality]}
+		}
+	}
+	return msg
+}
+
+// observationFromProto returns the observation to store. Its measurements
+// keep the units they were given in; normalize converts them.
+func observationFromProto(msg *weatherpb.Observation) Observation {
+	o := Observation{StationID: msg.GetStationId(), Description: msg.GetDescription()}
+	if msg.GetObservedAt() != nil {
+		o.ObservedAt = msg.GetObservedAt().AsTime()
+	}
+	fields := []*weatherpb.Measurement{
+		msg.GetTemperature(),
+		msg.GetHumidity(),
+		msg.GetPressure(),
+		msg.GetWindSpeed(),
+		msg.GetWindDirection(),
+		msg.GetPrecipitation(),
+		msg.GetVisibility(),
+	}
+	for i, m := range o.measurements() {
+		if fields[i] == nil {
+			continue
+		}
+		measurement := &Measurement{Value: fields[i].GetValue(), Unit: fields[i].GetUnit()}
+		for name, quality := range qualitiesToProto {
+			if quality == fields[i].GetQuality() {
+				measurement.Quality = name
+			}
+		}
+		*m = measurement
+	}
+	return o
+}
+
+func (weatherServer) CreateStation(ctx context.Context, req *weatherpb.CreateStationRequest) (*weatherpb.Station, error) {
+	s := req.GetStation()
+	station, err := createStation(ctx, Station{
+		ID:        s.GetId(),
+		Name:      s.GetName(),
+		Latitude:  s.Latitude,
+		Longitude: s.Longitude,
+	})
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to add station")
+	}
+	return stationToProto(station), nil
+}
+
+func (weatherServer) GetStation(ctx context.Context, req *weatherpb.GetStationRequest) (*weatherpb.Station, error) {
+	station, err := loadStation(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcError(ctx, err, "station not found", "failed to get station")
+	}
+	return stationToProto(station), nil
+}
+
+func (weatherServer) UpdateStation(ctx context.Context, req *weatherpb.UpdateStationRequest) (*weatherpb.Station, error) {
+	s := req.GetStation()
+	station, err := saveStation(ctx, s.GetId(), s.GetName(), s.Latitude, s.Longitude)
+	if err != nil {
+		return nil, grpcError(ctx, err, "station not found", "failed to update station")
+	}
+	return stationToProto(station), nil
+}
+
+func (weatherServer) DeleteStation(ctx context.Context, req *weatherpb.DeleteStationRequest) (*emptypb.Empty, error) {
+	if err := removeStation(ctx, req.GetId()); err != nil {
+		return nil, grpcError(ctx, err, "", "failed to delete station")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (weatherServer) ListStations(ctx context.Context, req *weatherpb.ListStationsRequest) (*weatherpb.ListStationsResponse, error) {
+	stations, err := loadStations(ctx)
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to query stations")
+	}
+	resp := &weatherpb.ListStationsResponse{}
+	for _, station := range stations {
+		resp.Stations = append(resp.Stations, stationToProto(station))
+	}
+	return resp, nil
+}
+
+func (weatherServer) CreateObservation(ctx context.Context, req *weatherpb.CreateObservationRequest) (*weatherpb.Observation, error) {
+	system, err := units(req.GetUnits())
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to add observation")
+	}
+	o := observationFromProto(req.GetObservation())
+	if err := createObservation(ctx, &o); err != nil {
+		return nil, grpcError(ctx, err, "station not found", "failed to add observation")
+	}
+	o.convertTo(system)
+	return observationToProto(o), nil
+}
+
+func (weatherServer) GetObservation(ctx context.Context, req *weatherpb.GetObservationRequest) (*weatherpb.Observation, error) {
+	system, err := units(req.GetUnits())
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to get observation")
+	}
+	o, err := loadObservation(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, grpcError(ctx, err, "observation not found", "failed to get observation")
+	}
+	o.convertTo(system)
+	return observationToProto(o), nil
+}
+
+func (weatherServer) UpdateObservation(ctx context.Context, req *weatherpb.UpdateObservationRequest) (*weatherpb.Observation, error) {
+	system, err := units(req.GetUnits())
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to update observation")
+	}
+	msg := req.GetObservation()
+	if msg.GetTemperature() == nil {
+		return nil, grpcError(ctx, invalidInputError("temperature is required"), "", "failed to update observation")
+	}
+	temperature := Measurement{Value: msg.GetTemperature().GetValue(), Unit: msg.GetTemperature().GetUnit()}
+	o, err := correctObservation(ctx, int(msg.GetId()), temperature, msg.GetDescription())
+	if err != nil {
+		return nil, grpcError(ctx, err, "observation not found", "failed to update observation")
+	}
+	o.convertTo(system)
+	return observationToProto(o), nil
+}
+
+func (weatherServer) DeleteObservation(ctx context.Context, req *weatherpb.DeleteObservationRequest) (*emptypb.Empty, error) {
+	if err := deleteObservationByID(ctx, strconv.Itoa(int(req.GetId()))); err != nil {
+		return nil, grpcError(ctx, err, "", "failed to delete observation")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (weatherServer) ListObservations(ctx context.Context, req *weatherpb.ListObservationsRequest) (*weatherpb.ListObservationsResponse, error) {
+	system, err := units(req.GetUnits())
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to query observations")
+	}
+	var fromStr, toStr string
+	if req.GetFrom() != nil {
+		fromStr = req.GetFrom().AsTime().Format(time.RFC3339Nano)
+	}
+	if req.GetTo() != nil {
+		toStr = req.GetTo().AsTime().Format(time.RFC3339Nano)
+	}
+	from, to, err := parseTimeRange(fromStr, toStr)
+	if err != nil {
+		return nil, grpcError(ctx, invalidInputError(err.Error()), "", "failed to query observations")
+	}
+	limit := int(req.GetLimit())
+	switch {
+	case limit < 0:
+		return nil, grpcError(ctx, invalidInputError("limit must be positive"), "", "failed to query observations")
+	case limit == 0:
+		limit = defaultObservationLimit
+	}
+
+	observations, err := loadStationObservations(ctx, req.GetStationId(), from, to, limit)
+	if err != nil {
+		return nil, grpcError(ctx, err, "", "failed to query observations")
+	}
+	resp := &weatherpb.ListObservationsResponse{}
+	for _, o := range observations {
+		o.convertTo(system)
+		resp.Observations = append(resp.Observations, observationToProto(o))
+	}
+	return resp, nil
+}
+
+func (weatherServer) Watch(req *weatherpb.WatchRequest, stream weatherpb.Weather_WatchServer) error {
+	ctx := stream.Context()
+	system, err := units(req.GetUnits())
+	if err != nil {
+		return grpcError(ctx, err, "", "failed to stream events")
+	}
+	since := int64(-1)
+	if req.SinceEventId != nil {
+		since = req.GetSinceEventId()
+	}
+	err = followEvents(ctx, since, func(e Event) error {
+		msg := &weatherpb.WeatherEvent{
+			EventId:   e.ID,
+			Action:    actionsToProto[e.Action],
+			CreatedAt: timestamppb.New(e.CreatedAt),
+		}
+		switch e.Resource {
+		case "station":
+			station := Station{ID: e.ResourceID}
+			if e.Data != nil {
+				if err := json.Unmarshal(e.Data, &station); err != nil {
+					return fmt.Errorf("event %d: %w", e.ID, err)
+				}
+			}
+			msg.Resource = &weatherpb.WeatherEvent_Station{Station: stationToProto(station)}
+		case "observation":
+			id, err := strconv.Atoi(e.ResourceID)
+			if err != nil {
+				return fmt.Errorf("event %d: %w", e.ID, err)
+			}
+			o := Observation{ID: id}
+			if e.Data != nil {
+				if err := json.Unmarshal(e.Data, &o); err != nil {
+					return fmt.Errorf("event %d: %w", e.ID, err)
+				}
+				o.convertTo(system)
+			}
+			msg.Resource = &weatherpb.WeatherEvent_Observation{Observation: observationToProto(o)}
+		default:
+			return nil
+		}
+		return stream.Send(msg)
+	}, func() error { return nil })
+	return watchError(err)
+}
@@ -0,0 +1,561 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultImportBatchSize = 500
+	maxImportErrors        = 100
+)
+
+// importRecord is one parsed row or report: the observation and the station
+// it belongs to, which is created on the fly if unknown.
+type importRecord struct {
+	Line        int
+	Station     Station
+	Observation Observation
+}
+
+// ImportError describes a row that could not be imported.
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportReport summarises an import. It is also sent as progress after
+// every committed batch.
+type ImportReport struct {
+	Format     string        `json:"format"`
+	Parsed     int           `json:"parsed"`
+	Inserted   int           `json:"inserted"`
+	Duplicates int           `json:"duplicates"`
+	Failed     int           `json:"failed"`
+	Batches    int           `json:"batches"`
+	Errors     []ImportError `json:"errors,omitempty"`
+	Elapsed    string        `json:"elapsed"`
+	Done       bool          `json:"done"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// CSVMapping tells the importer which CSV columns, looked up by header name,
+// hold which values. Metrics map observation metric names to columns.
+type CSVMapping struct {
+	Delimiter   string               `json:"delimiter"`
+	Station     string               `json:"station"`
+	StationName string               `json:"station_name"`
+	Latitude    string               `json:"latitude"`
+	Longitude   string               `json:"longitude"`
+	Time        string               `json:"time"`
+	TimeFormat  string               `json:"time_format"`
+	Description string               `json:"description"`
+	Metrics     map[string]CSVColumn `json:"metrics"`
+}
+
+// CSVColumn is a metric column and the unit and quality flag of its values.
+// Unit defaults to the metric's canonical unit and Quality to good.
+type CSVColumn struct {
+	Column  string `json:"column"`
+	Unit    string `json:"unit"`
+	Quality string `json:"quality"`
+}
+
+// defaultCSVMapping expects a "station" and a "time" column in RFC 3339 and
+// one column per metric named after it.
+func defaultCSVMapping() CSVMapping {
+	mapping := CSVMapping{Station: "station", Time: "time", Description: "description", Metrics: map[string]CSVColumn{}}
+	for _, m := range observationMetrics {
+		mapping.Metrics[m.Name] = CSVColumn{Column: m.Name}
+	}
+	return mapping
+}
+
+// importOptions configures a single import.
+type importOptions struct {
+	Format    string
+	Mapping   CSVMapping
+	Month     time.Time // reference month for METAR day-of-month timestamps
+	MonthSet  bool
+	BatchSize int
+	Progress  func(ImportReport)
+}
+
+// parseImportOptions validates the settings shared by the endpoint and the
+// CLI subcommand.
+func parseImportOptions(format, mapping, month string, batchSize int) (importOptions, error) {
+	opts := importOptions{Format: strings.ToLower(format), Mapping: defaultCSVMapping(), Month: time.Now().UTC(), BatchSize: batchSize}
+	if opts.Format != "metar" && opts.Format != "csv" {
+		return opts, fmt.Errorf("Unknown format %q, expected metar or csv", format)
+	}
+	if mapping != "" {
+		opts.Mapping = CSVMapping{}
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			return opts, fmt.Errorf("Invalid CSV mapping: %v", err)
+		}
+		if opts.Mapping.Station == "" || opts.Mapping.Time == "" {
+			return opts, errors.New("CSV mapping needs station and time columns")
+		}
+		for name, col := range opts.Mapping.Metrics {
+			if metricIndex(name) < 0 {
+				return opts, fmt.Errorf("Unknown metric %q in CSV mapping", name)
+			}
+			if col.Quality != "" && !validQuality(col.Quality) {
+				return opts, fmt.Errorf("Invalid quality flag %q in CSV mapping", col.Quality)
+			}
+		}
+	}
+	if month != "" {
+		t, err := time.Parse("2006-01", month)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid month %q, expected YYYY-MM", month)
+		}
+		// Any day works as long as every report day fits the month.
+		opts.Month, opts.MonthSet = t.AddDate(0, 0, 27), true
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	return opts, nil
+}
+
+// importer accumulates parsed records and writes them in batches, one
+// transaction per batch.
+type importer struct {
+	opts    importOptions
+	report  ImportReport
+	batch   []importRecord
+	started time.Time
+}
+
+func runImport(r io.Reader, opts importOptions) (ImportReport, error) {
+	imp := &importer{opts: opts, report: ImportReport{Format: opts.Format}, started: time.Now()}
+
+	var err error
+	if opts.Format == "metar" {
+		err = imp.readMETAR(r)
+	} else {
+		err = imp.readCSV(r)
+	}
+	if err == nil {
+		err = imp.flush()
+	}
+
+	imp.report.Done = true
+	imp.report.Elapsed = time.Since(imp.started).Round(time.Millisecond).String()
+	if err != nil {
+		imp.report.Error = err.Error()
+	}
+	return imp.report, err
+}
+
+func (imp *importer) fail(line int, err error) {
+	imp.report.Failed++
+	if len(imp.report.Errors) < maxImportErrors {
+		imp.report.Errors = append(imp.report.Errors, ImportError{Line: line, Error: err.Error()})
+	}
+}
+
+func (imp *importer) add(rec importRecord) error {
+	if err := rec.Observation.normalize(); err != nil {
+		imp.fail(rec.Line, err)
+		return nil
+	}
+	imp.report.Parsed++
+	imp.batch = append(imp.batch, rec)
+	if len(imp.batch) >= imp.opts.BatchSize {
+		return imp.flush()
+	}
+	return nil
+}
+
+// flush writes the pending batch. Rows already stored for the same station
+// and timestamp, in the database or earlier in the batch, are skipped, even
+// when another import or the API stores them at the same time. Each
+// stored observation and new station is published like one added through the
+// API, so streams, web2 and webhooks see imported data. The events are
+// published together just before commit, so the lock publishEvent takes is
+// held only while they are written rather than for the whole batch.
+func (imp *importer) flush() error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	type pendingEvent struct {
+		resource, resourceID string
+		data                 any
+	}
+	var created []pendingEvent
+	inserted, duplicates := 0, 0
+	for i := range imp.batch {
+		rec := &imp.batch[i]
+
+		station, err := scanStation(tx.QueryRow(
+			`INSERT INTO stations (id, name, latitude, longitude) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO NOTHING
+			RETURNING `+stationColumns,
+			rec.Station.ID, rec.Station.Name, rec.Station.Latitude, rec.Station.Longitude,
+		))
+		switch {
+		case err == nil:
+			created = append(created, pendingEvent{"station", station.ID, station})
+		case err != sql.ErrNoRows:
+			return err
+		}
+
+		rec.Observation.ObservedAt = rec.Observation.ObservedAt.UTC()
+		err = storeObservation(tx, &rec.Observation)
+		if errors.Is(err, errConflict) {
+			duplicates++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", rec.Line, err)
+		}
+		created = append(created, pendingEvent{"observation", strconv.Itoa(rec.Observation.ID), rec.Observation})
+		inserted++
+	}
+	for _, e := range created {
+		if err := publishEvent(tx, e.resource, "created", e.resourceID, e.data); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	imp.batch = imp.batch[:0]
+	imp.report.Inserted += inserted
+	imp.report.Duplicates += duplicates
+	imp.report.Batches++
+	if imp.opts.Progress != nil {
+		progress := imp.report
+		progress.Elapsed = time.Since(imp.started).Round(time.Millisecond).String()
+		imp.opts.Progress(progress)
+	}
+	return nil
+}
+
+// readMETAR reads one report per line. Lines such as "2024/01/12 17:56", as
+// found in NOAA archives, set the date of the report that follows.
+func (imp *importer) readMETAR(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	var dated *time.Time
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if metarDateLine.MatchString(text) {
+			if t, err := time.Parse("2006/01/02 15:04", text); err == nil {
+				dated = &t
+			}
+			continue
+		}
+
+		ref, monthKnown := imp.opts.Month, imp.opts.MonthSet
+		if dated != nil {
+			ref, monthKnown = *dated, true
+			dated = nil
+		}
+		rec, err := parseMETAR(text, ref, monthKnown)
+		if err == errMETARNil {
+			continue
+		}
+		if err != nil {
+			imp.fail(line, err)
+			continue
+		}
+		rec.Line = line
+		if err := imp.add(rec); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// readCSV reads a CSV file with a header row using the configured mapping.
+func (imp *importer) readCSV(r io.Reader) error {
+	mapping := imp.opts.Mapping
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		reader.Comma = []rune(mapping.Delimiter)[0]
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	index := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		if i, ok := columns[strings.ToLower(name)]; ok {
+			return i
+		}
+		return -1
+	}
+	if index(mapping.Station) < 0 || index(mapping.Time) < 0 {
+		return fmt.Errorf("CSV header is missing the %q or %q column", mapping.Station, mapping.Time)
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line++
+		if err != nil {
+			imp.fail(line, err)
+			continue
+		}
+		cell := func(name string) string {
+			if i := index(name); i >= 0 && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		rec, err := csvRecord(mapping, cell)
+		if err != nil {
+			imp.fail(line, err)
+			continue
+		}
+		rec.Line = line
+		if err := imp.add(rec); err != nil {
+			return err
+		}
+	}
+}
+
+func csvRecord(mapping CSVMapping, cell func(string) string) (importRecord, error) {
+	var rec importRecord
+	stationID := cell(mapping.Station)
+	if stationID == "" {
+		return rec, errors.New("missing station")
+	}
+	rec.Station = Station{ID: stationID, Name: cell(mapping.StationName)}
+	if rec.Station.Name == "" {
+		rec.Station.Name = stationID
+	}
+	for _, c := range []struct {
+		column string
+		dest   **float64
+	}{{mapping.Latitude, &rec.Station.Latitude}, {mapping.Longitude, &rec.Station.Longitude}} {
+		if v := cell(c.column); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return rec, fmt.Errorf("invalid %s %q", c.column, v)
+			}
+			*c.dest = &f
+		}
+	}
+
+	observedAt, err := parseImportTime(cell(mapping.Time), mapping.TimeFormat)
+	if err != nil {
+		return rec, err
+	}
+	rec.Observation = Observation{StationID: stationID, ObservedAt: observedAt, Description: cell(mapping.Description)}
+
+	measurements := rec.Observation.measurements()
+	for name, col := range mapping.Metrics {
+		v := cell(col.Column)
+		if v == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return rec, fmt.Errorf("invalid %s %q", name, v)
+		}
+		*measurements[metricIndex(name)] = &Measurement{Value: value, Unit: col.Unit, Quality: col.Quality}
+	}
+	return rec, nil
+}
+
+// parseImportTime parses a timestamp with a Go time layout, RFC 3339 by
+// default, or as Unix seconds when layout is "unix".
+func parseImportTime(value, layout string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("missing time")
+	}
+	if layout == "unix" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix time %q", value)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return t, fmt.Errorf("invalid time %q", value)
+	}
+	return t.UTC(), nil
+}
+
+// importWeather serves POST /weather/import. The file is either the raw
+// request body, with settings in the query string, or the "file" part of a
+// multipart form whose other fields carry the settings. Settings are format
+// (metar or csv), mapping (CSV mapping as JSON), month (YYYY-MM, for METAR)
+// and batch (rows per transaction). Clients accepting application/x-ndjson
+// get a progress line after every batch.
+func importWeather(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	value := r.URL.Query().Get
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body, value = file, r.FormValue
+	}
+
+	batchSize := 0
+	if s := value("batch"); s != "" {
+		var err error
+		if batchSize, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "Invalid batch size", http.StatusBadRequest)
+			return
+		}
+	}
+	opts, err := parseImportOptions(value("format"), value("mapping"), value("month"), batchSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, streaming := w.(http.Flusher)
+	streaming = streaming && strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	if streaming {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		opts.Progress = func(progress ImportReport) {
+			encoder.Encode(progress)
+			flusher.Flush()
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	report, err := runImport(body, opts)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Weather import failed", "error", err)
+		if !streaming {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	encoder.Encode(report)
+}
+
+// runImportCommand implements "main import [flags] file...", loading files
+// straight into the database. Progress goes to stderr, the final report to stdout.
+func runImportCommand(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dsn := flags.String("db", databaseURL, "Postgres connection string")
+	format := flags.String("format", "metar", "input format: metar or csv")
+	mappingFile := flags.String("mapping", "", "JSON file with the CSV column mapping")
+	month := flags.String("month", "", "reference month YYYY-MM for METAR timestamps (default: current month)")
+	batchSize := flags.Int("batch", defaultImportBatchSize, "rows per transaction")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import [flags] file... (use - for stdin)\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	mapping := ""
+	if *mappingFile != "" {
+		data, err := os.ReadFile(*mappingFile)
+		if err != nil {
+			slog.Error("Failed to read mapping", "file", *mappingFile, "error", err)
+			return 1
+		}
+		mapping = string(data)
+	}
+	opts, err := parseImportOptions(*format, mapping, *month, *batchSize)
+	if err != nil {
+		slog.Error("Invalid import options", "error", err)
+		return 2
+	}
+
+	db, err = sql.Open("postgres", *dsn)
+	if err != nil {
+		slog.Error("Failed to connect to DB", "error", err)
+		return 1
+	}
+	defer db.Close()
+	if err := ensureTableExists(); err != nil {
+		slog.Error("Failed to ensure table exists", "error", err)
+		return 1
+	}
+
+	status := 0
+	encoder := json.NewEncoder(os.Stdout)
+	for _, name := range flags.Args() {
+		if err := importFile(name, opts, encoder); err != nil {
+			slog.Error("Import failed", "file", name, "error", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// importFile imports the file called name, or stdin for "-", writes its
+// report to encoder and closes the file once done.
+func importFile(name string, opts importOptions, encoder *json.Encoder) error {
+	var in io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	opts.Progress = func(p ImportReport) {
+		slog.Info("Import progress", "file", name, "batch", p.Batches, "parsed", p.Parsed, "inserted", p.Inserted,
+			"duplicates", p.Duplicates, "failed", p.Failed, "elapsed", p.Elapsed)
+	}
+	report, err := runImport(in, opts)
+	encoder.Encode(report)
+	return err
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logLevelChannel carries log level changes to every instance.
+const logLevelChannel = "weather_log_level"
+
+// maxLoggedError bounds how much of an error response is copied into the
+// request's log line.
+const maxLoggedError = 512
+
+// logLevel is the minimum level logged. It starts at LOG_LEVEL, or info, and
+// can be changed at runtime through /admin/log-level.
+var logLevel = new(slog.LevelVar)
+
+// quietRoutes are polled by Consul and Prometheus; their successful requests
+// are only logged at debug level.
+var quietRoutes = map[string]bool{"/health": true, "/livez": true, "/readyz": true, "/startupz": true, "/metrics": true}
+
+// setupLogging makes a JSON handler the default logger, so slog calls and the
+// standard log package both write one JSON object per line, tagged with the
+// service and instance.
+func setupLogging() {
+	if err := logLevel.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		logLevel.Set(slog.LevelInfo)
+	}
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})
+	slog.SetDefault(slog.New(contextHandler{handler}).With("service", "weather", "instance", serviceID))
+}
+
+// fatal logs err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// contextHandler adds the request and trace IDs carried by the context given
+// to slog's Context functions.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := middleware.GetReqID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// logRequests logs a line for every request once it has been handled: client
+// errors at warn, server errors at error with the message sent to the client.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lw := &logWriter{ResponseWriter: w}
+		next.ServeHTTP(lw, r)
+
+		if lw.status == 0 {
+			lw.status = http.StatusOK
+		}
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		level := slog.LevelInfo
+		switch {
+		case lw.status >= 500:
+			level = slog.LevelError
+		case lw.status >= 400:
+			level = slog.LevelWarn
+		case quietRoutes[route]:
+			level = slog.LevelDebug
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", lw.status),
+			slog.Int("bytes", lw.bytes),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+		}
+		if len(lw.errorBody) > 0 {
+			attrs = append(attrs, slog.String("error", strings.TrimSpace(string(lw.errorBody))))
+		}
+		slog.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+type logWriter struct {
+	http.ResponseWriter
+	status    int
+	bytes     int
+	errorBody []byte
+}
+
+func (w *logWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *logWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status >= 400 && len(w.errorBody) < maxLoggedError {
+		w.errorBody = append(w.errorBody, b[:min(len(b), maxLoggedError-len(w.errorBody))]...)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *logWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type logLevelBody struct {
+	Level string `json:"level"`
+}
+
+func getLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logLevelBody{Level: logLevel.Level().String()})
+}
+
+// setLogLevel changes the log level of every instance: the change is
+// published on logLevelChannel and applied by each instance's event listener.
+func setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var input logLevelBody
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(input.Level)); err != nil {
+		http.Error(w, "level must be debug, info, warn or error", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := db.ExecContext(r.Context(), `SELECT pg_notify($1, $2)`, logLevelChannel, level.String()); err != nil {
+		http.Error(w, "Failed to set log level", http.StatusInternalServerError)
+		return
+	}
+	applyLogLevel(level.String())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logLevelBody{Level: level.String()})
+}
+
+// applyLogLevel sets the level received on logLevelChannel.
+func applyLogLevel(text string) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(text)); err != nil {
+		slog.Warn("Ignoring invalid log level", "level", text)
+		return
+	}
+	if level != logLevel.Level() {
+		logLevel.Set(level)
+		slog.Info("Log level changed", "level", level.String())
+	}
+}
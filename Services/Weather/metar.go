@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	metarStation    = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+	metarTime       = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	metarWind       = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	metarWindVar    = regexp.MustCompile(`^\d{3}V\d{3}$`)
+	metarVisMeters  = regexp.MustCompile(`^(\d{4})(?:NDV)?$`)
+	metarVisMiles   = regexp.MustCompile(`^M?(\d+)(?:/(\d+))?SM$`)
+	metarRVR        = regexp.MustCompile(`^R\d{2}[LCR]?/`)
+	metarWeather    = regexp.MustCompile(`^(\+|-|VC)?(MI|PR|BC|DR|BL|SH|TS|FZ)?((?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)+)$`)
+	metarCloud      = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3}|///)(?:CB|TCU)?$`)
+	metarTemp       = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?$`)
+	metarAltimeter  = regexp.MustCompile(`^A(\d{4})$`)
+	metarQNH        = regexp.MustCompile(`^Q(\d{4})$`)
+	metarHourlyRain = regexp.MustCompile(`^P(\d{4})$`)
+	metarDateLine   = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}$`)
+)
+
+var metarPhenomena = map[string]string{
+	"DZ": "drizzle", "RA": "rain", "SN": "snow", "SG": "snow grains", "IC": "ice crystals",
+	"PL": "ice pellets", "GR": "hail", "GS": "small hail", "UP": "unknown precipitation",
+	"BR": "mist", "FG": "fog", "FU": "smoke", "VA": "volcanic ash", "DU": "dust", "SA": "sand",
+	"HZ": "haze", "PY": "spray", "PO": "dust whirls", "SQ": "squalls", "FC": "funnel cloud",
+	"SS": "sandstorm", "DS": "duststorm",
+}
+
+var metarDescriptors = map[string]string{
+	"MI": "shallow", "PR": "partial", "BC": "patches of", "DR": "drifting", "BL": "blowing",
+	"SH": "showers of", "TS": "thunderstorm with", "FZ": "freezing",
+}
+
+var metarCloudCover = map[string]string{
+	"SKC": "clear", "CLR": "clear", "NSC": "no significant cloud", "NCD": "no cloud detected",
+	"FEW": "few clouds", "SCT": "scattered clouds", "BKN": "broken clouds", "OVC": "overcast", "VV": "sky obscured",
+}
+
+// metarCloudRank orders cloud cover so the description names the most significant layer.
+var metarCloudRank = map[string]int{"FEW": 1, "SCT": 2, "BKN": 3, "OVC": 4, "VV": 5}
+
+var errMETARNil = errors.New("NIL report")
+
+// parseMETAR decodes a single METAR or SPECI report. The report only carries
+// day and time, so year and month come from ref; when monthKnown is false a
+// day after ref's day is taken to be in the previous month.
+func parseMETAR(report string, ref time.Time, monthKnown bool) (importRecord, error) {
+	var rec importRecord
+	tokens := strings.Fields(strings.TrimRight(strings.TrimSpace(report), "="))
+	if len(tokens) > 0 && (tokens[0] == "METAR" || tokens[0] == "SPECI") {
+		tokens = tokens[1:]
+	}
+	if len(tokens) < 2 || !metarStation.MatchString(tokens[0]) {
+		return rec, errors.New("missing station identifier")
+	}
+	rec.Station = Station{ID: tokens[0], Name: tokens[0]}
+	rec.Observation.StationID = tokens[0]
+
+	match := metarTime.FindStringSubmatch(tokens[1])
+	if match == nil {
+		return rec, fmt.Errorf("invalid observation time %q", tokens[1])
+	}
+	day, _ := strconv.Atoi(match[1])
+	hour, _ := strconv.Atoi(match[2])
+	minute, _ := strconv.Atoi(match[3])
+	month := ref.Month()
+	if !monthKnown && day > ref.Day() {
+		month--
+	}
+	rec.Observation.ObservedAt = time.Date(ref.Year(), month, day, hour, minute, 0, 0, time.UTC)
+
+	o := &rec.Observation
+	var phenomena []string
+	cloud := ""
+	remarks := false
+	for i := 2; i < len(tokens); i++ {
+		tok := strings.TrimSuffix(tokens[i], "$")
+		if remarks {
+			if m := metarHourlyRain.FindStringSubmatch(tok); m != nil {
+				hundredths, _ := strconv.Atoi(m[1])
+				o.Precipitation = &Measurement{Value: round1(float64(hundredths) / 100 * 25.4), Quality: qualityGood}
+			}
+			continue
+		}
+
+		switch {
+		case tok == "NIL":
+			return rec, errMETARNil
+		case tok == "AUTO" || tok == "COR" || tok == "NOSIG":
+		case tok == "RMK":
+			remarks = true
+		case tok == "BECMG" || tok == "TEMPO":
+			// Trend groups forecast the weather, they do not observe it.
+			remarks = true
+		case tok == "CAVOK":
+			o.Visibility = &Measurement{Value: 10, Quality: qualityGood}
+			cloud = "CAVOK"
+		case metarWind.MatchString(tok):
+			m := metarWind.FindStringSubmatch(tok)
+			speed, _ := strconv.ParseFloat(m[2], 64)
+			switch m[4] {
+			case "KT":
+				speed *= 1.852
+			case "MPS":
+				speed *= 3.6
+			}
+			o.WindSpeed = &Measurement{Value: round1(speed), Quality: qualityGood}
+			if m[1] != "VRB" && speed > 0 {
+				direction, _ := strconv.ParseFloat(m[1], 64)
+				o.WindDirection = &Measurement{Value: direction, Quality: qualityGood}
+			}
+		case metarWindVar.MatchString(tok), metarRVR.MatchString(tok):
+		case o.Visibility == nil && metarVisMeters.MatchString(tok):
+			meters, _ := strconv.ParseFloat(metarVisMeters.FindStringSubmatch(tok)[1], 64)
+			o.Visibility = &Measurement{Value: meters / 1000, Quality: qualityGood}
+		case o.Visibility == nil && metarVisMiles.MatchString(tok):
+			m := metarVisMiles.FindStringSubmatch(tok)
+			miles, _ := strconv.ParseFloat(m[1], 64)
+			if m[2] != "" {
+				denominator, _ := strconv.ParseFloat(m[2], 64)
+				miles /= denominator
+				// A whole number before the fraction, as in "1 1/2SM".
+				if whole, err := strconv.Atoi(tokens[i-1]); err == nil {
+					miles += float64(whole)
+				}
+			}
+			o.Visibility = &Measurement{Value: round1(miles * 1.609344), Quality: qualityGood}
+		case metarWeather.MatchString(tok):
+			phenomena = append(phenomena, describeMETARWeather(metarWeather.FindStringSubmatch(tok)))
+		case metarCloud.MatchString(tok):
+			cover := metarCloud.FindStringSubmatch(tok)[1]
+			if metarCloudRank[cover] > metarCloudRank[cloud] {
+				cloud = cover
+			}
+		case metarCloudCover[tok] != "":
+			if cloud == "" {
+				cloud = tok
+			}
+		case metarTemp.MatchString(tok):
+			m := metarTemp.FindStringSubmatch(tok)
+			temperature := metarDegrees(m[1])
+			o.Temperature = &Measurement{Value: temperature, Quality: qualityGood}
+			if m[2] != "" {
+				o.Humidity = &Measurement{Value: relativeHumidity(temperature, metarDegrees(m[2])), Quality: qualityEstimated}
+			}
+		case metarAltimeter.MatchString(tok):
+			inHg, _ := strconv.ParseFloat(metarAltimeter.FindStringSubmatch(tok)[1], 64)
+			o.Pressure = &Measurement{Value: round1(inHg / 100 * 33.8639), Quality: qualityGood}
+		case metarQNH.MatchString(tok):
+			hPa, _ := strconv.ParseFloat(metarQNH.FindStringSubmatch(tok)[1], 64)
+			o.Pressure = &Measurement{Value: hPa, Quality: qualityGood}
+		}
+	}
+
+	switch {
+	case len(phenomena) > 0:
+		o.Description = strings.Join(phenomena, ", ")
+	case cloud == "CAVOK":
+		o.Description = "ceiling and visibility OK"
+	case cloud != "":
+		o.Description = metarCloudCover[cloud]
+	}
+	return rec, nil
+}
+
+func describeMETARWeather(m []string) string {
+	var words []string
+	switch m[1] {
+	case "-":
+		words = append(words, "light")
+	case "+":
+		words = append(words, "heavy")
+	case "VC":
+		words = append(words, "nearby")
+	}
+	if m[2] != "" {
+		words = append(words, metarDescriptors[m[2]])
+	}
+	for i := 0; i+2 <= len(m[3]); i += 2 {
+		words = append(words, metarPhenomena[m[3][i:i+2]])
+	}
+	return strings.Join(words, " ")
+}
+
+// metarDegrees parses a METAR temperature such as "12" or "M03".
+func metarDegrees(s string) float64 {
+	negative := strings.HasPrefix(s, "M")
+	v, _ := strconv.ParseFloat(strings.TrimPrefix(s, "M"), 64)
+	if negative {
+		return -v
+	}
+	return v
+}
+
+// relativeHumidity estimates relative humidity in percent from temperature
+// and dew point in °C using the Magnus formula.
+func relativeHumidity(temperature, dewPoint float64) float64 {
+	const b, c = 17.625, 243.04
+	rh := 100 * math.Exp(b*dewPoint/(c+dewPoint)) / math.Exp(b*temperature/(c+temperature))
+	return round1(math.Min(rh, 100))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// metricsQueryTimeout bounds the queries run for each scrape so a slow
+// database cannot stall Prometheus.
+const metricsQueryTimeout = 2 * time.Second
+
+var (
+	requestLabels = []string{"route", "method", "status"}
+	rpcLabels     = []string{"method", "code"}
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by route pattern, method and status.",
+	}, requestLabels)
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests, by route pattern, method and status.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, requestLabels)
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_requests_total",
+		Help: "gRPC calls handled, by method and status code.",
+	}, rpcLabels)
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_request_duration_seconds",
+		Help:    "Time taken to handle gRPC calls, by method and status code; Watch calls last as long as the client listens.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, rpcLabels)
+	consulRegistered = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "consul_registered",
+		Help: "1 while this instance is registered with Consul, otherwise 0.",
+	})
+)
+
+// registerMetrics registers the service's collectors with the default
+// registry, which already exports Go runtime and process metrics. It needs db.
+func registerMetrics() {
+	prometheus.MustRegister(
+		httpRequests,
+		httpDuration,
+		grpcRequests,
+		grpcDuration,
+		consulRegistered,
+		collectors.NewDBStatsCollector(db, "weather"),
+		domainCollector{},
+	)
+}
+
+// instrumentRequests records the count and latency of every request, labelled
+// with the matched route pattern rather than the path to keep cardinality low.
+func instrumentRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		labels := prometheus.Labels{"route": route, "method": r.Method, "status": strconv.Itoa(status)}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+var (
+	readingAgeDesc = prometheus.NewDesc("weather_last_observation_age_seconds",
+		"Seconds since each station's most recent observation.", []string{"station"}, nil)
+	pendingAnomaliesDesc = prometheus.NewDesc("weather_anomalies_pending",
+		"Quarantined readings waiting for review.", nil, nil)
+	webhookDeliveriesDesc = prometheus.NewDesc("webhook_deliveries",
+		"Webhook deliveries by status; dead deliveries wait for a manual retry.", []string{"status"}, nil)
+)
+
+// domainCollector reads the domain gauges from the database on each scrape,
+// so every instance reports the same values.
+type domainCollector struct{}
+
+func (domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- readingAgeDesc
+	ch <- pendingAnomaliesDesc
+	ch <- webhookDeliveriesDesc
+}
+
+func (domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsQueryTimeout)
+	defer cancel()
+
+	if err := collectReadingAges(ctx, ch); err != nil {
+		ch <- prometheus.NewInvalidMetric(readingAgeDesc, err)
+	}
+
+	var pending float64
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM weather_anomalies WHERE status = $1`, anomalyPending).Scan(&pending); err != nil {
+		ch <- prometheus.NewInvalidMetric(pendingAnomaliesDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(pendingAnomaliesDesc, prometheus.GaugeValue, pending)
+	}
+
+	deliveries := map[string]float64{deliveryPending: 0, deliveryDelivered: 0, deliveryDead: 0}
+	if err := countBy(ctx, `SELECT status, COUNT(*) FROM weather_webhook_deliveries GROUP BY status`, deliveries); err != nil {
+		ch <- prometheus.NewInvalidMetric(webhookDeliveriesDesc, err)
+	} else {
+		for status, n := range deliveries {
+			ch <- prometheus.MustNewConstMetric(webhookDeliveriesDesc, prometheus.GaugeValue, n, status)
+		}
+	}
+}
+
+func collectReadingAges(ctx context.Context, ch chan<- prometheus.Metric) error {
+	rows, err := db.QueryContext(ctx, `SELECT station_id, MAX(observed_at) FROM observations GROUP BY station_id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	now := time.Now().UTC()
+	for rows.Next() {
+		var station string
+		var observedAt time.Time
+		if err := rows.Scan(&station, &observedAt); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(readingAgeDesc, prometheus.GaugeValue, now.Sub(observedAt).Seconds(), station)
+	}
+	return rows.Err()
+}
+
+// countBy adds the (key, count) rows returned by query to counts.
+func countBy(ctx context.Context, query string, counts map[string]float64) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		var n float64
+		if err := rows.Scan(&key, &n); err != nil {
+			return err
+		}
+		counts[key] += n
+	}
+	return rows.Err()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Lists are returned in pages when a limit is given: ?limit=N returns the
+// first N rows by id and ?after=ID the N rows following that id. While rows
+// remain, a Link header with rel="next" gives the URL of the next page.
+// Without a limit a list returns every row, as it always has.
+
+const maxPageSize = 1000
+
+// page is the part of a list a request asks for. limit is 0 when the request
+// asks for the whole list.
+type page struct {
+	limit int
+	after int
+}
+
+func parsePage(r *http.Request) (page, error) {
+	var p page
+	query := r.URL.Query()
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxPageSize {
+			return p, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		p.limit = limit
+	}
+	if s := query.Get("after"); s != "" {
+		after, err := strconv.Atoi(s)
+		if err != nil || after < 0 {
+			return p, errors.New("after must be a row id")
+		}
+		p.after = after
+	}
+	return p, nil
+}
+
+// clause returns the end of a list query, ordering it by id and, for a
+// limited page, restricting it to the rows after p.after plus one more, which
+// tells whether another page follows. Its placeholders start at $n.
+func (p page) clause(n int) (string, []any) {
+	if p.limit == 0 {
+		return ` ORDER BY id`, nil
+	}
+	return fmt.Sprintf(` WHERE id > $%d ORDER BY id LIMIT $%d`, n, n+1), []any{p.after, p.limit + 1}
+}
+
+// more reports whether a page of count rows, fetched with clause, has rows
+// after it; the extra row must then be dropped.
+func (p page) more(count int) bool {
+	return p.limit > 0 && count > p.limit
+}
+
+// setNextPage links to the page following the row with id lastID, keeping the
+// request's other query parameters.
+func setNextPage(w http.ResponseWriter, r *http.Request, lastID int) {
+	query := r.URL.Query()
+	query.Set("after", strconv.Itoa(lastID))
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, query.Encode()))
+}
+
+// newPage is the page a gRPC List call asks for with page_size and after_id,
+// checked like parsePage checks ?limit and ?after. A page size of 0 asks for
+// the whole list.
+func newPage(size, after int) (page, error) {
+	if size < 0 || size > maxPageSize {
+		return page{}, invalidInputError(fmt.Sprintf("page_size must be between 0 and %d", maxPageSize))
+	}
+	if after < 0 {
+		return page{}, invalidInputError("after_id must be a row id")
+	}
+	return page{limit: size, after: after}, nil
+}
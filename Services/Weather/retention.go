@@ -0,0 +1,491 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	retentionInterval   = time.Hour       // how often a compaction run is due
+	retentionCheckEvery = 5 * time.Minute // how often each replica checks whether one is due
+	retentionRunTimeout = 30 * time.Minute
+)
+
+var errRetentionBusy = errors.New("a retention run is already in progress")
+
+// retentionResult counts what one policy did in a run.
+type retentionResult struct {
+	Deleted     int64  `json:"deleted"`
+	Downsampled int64  `json:"downsampled"`
+	Error       string `json:"error,omitempty"`
+}
+
+// retentionPolicy prunes one kind of history older than the cutoff, first
+// downsampling it into coarser data where there is any.
+type retentionPolicy struct {
+	Name            string
+	Description     string
+	DefaultKeepDays int // 0 keeps rows forever
+	apply           func(ctx context.Context, cutoff time.Time) (retentionResult, error)
+}
+
+var retentionPolicies = []retentionPolicy{
+	{"observations", "Raw observations, downsampled into the 1h and 1d rollups", 30, pruneObservations},
+	{"rollups_1h", "Hourly rollups, downsampled into the 1d rollups", 730, pruneRollups("1h", "1d")},
+	{"rollups_1d", "Daily rollups", 0, pruneRollups("1d", "")},
+	{"alerts", "Resolved alerts", 180, pruneRows(`DELETE FROM alerts WHERE state = 'resolved' AND resolved_at < $1`)},
+	{"anomalies", "Reviewed anomalies", 90, pruneRows(`DELETE FROM weather_anomalies WHERE status <> 'pending' AND reviewed_at < $1`)},
+	{"retention_runs", "History of retention runs", 30, pruneRows(`DELETE FROM retention_runs WHERE started_at < $1`)},
+	{"events", "Change events kept for /events resumption", 7, pruneRows(`DELETE FROM weather_events WHERE created_at < $1`)},
+	{"webhook_deliveries", "Delivered webhook deliveries", 30, pruneRows(`DELETE FROM weather_webhook_deliveries WHERE status = 'delivered' AND delivered_at < $1`)},
+}
+
+func retentionPolicyByName(name string) (retentionPolicy, bool) {
+	for _, p := range retentionPolicies {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return retentionPolicy{}, false
+}
+
+// retentionTables returns the statements creating the retention tables and
+// seeding a row per policy with its default, leaving operator changes alone.
+func retentionTables() []string {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS retention_policies (
+			name TEXT PRIMARY KEY,
+			keep_days INTEGER NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS retention_runs (
+			id SERIAL PRIMARY KEY,
+			instance TEXT NOT NULL,
+			started_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP,
+			results JSONB NOT NULL DEFAULT '{}',
+			error TEXT NOT NULL DEFAULT ''
+		)`,
+	}
+	for _, p := range retentionPolicies {
+		statements = append(statements, fmt.Sprintf(
+			`INSERT INTO retention_policies (name, keep_days) VALUES ('%s', %d) ON CONFLICT (name) DO NOTHING`,
+			p.Name, p.DefaultKeepDays,
+		))
+	}
+	return statements
+}
+
+// pruneObservations deletes raw observations before the cutoff one day at a
+// time. Each day's rollups are recomputed from its raw rows in the same
+// transaction first, so the rollups keep exactly what is being deleted. The
+// cutoff is day aligned, so a day is never left half deleted for a later run
+// to recompute from.
+func pruneObservations(ctx context.Context, cutoff time.Time) (retentionResult, error) {
+	var result retentionResult
+	for {
+		var oldest sql.NullTime
+		if err := db.QueryRowContext(ctx, `SELECT MIN(observed_at) FROM observations WHERE observed_at < $1`, cutoff).Scan(&oldest); err != nil {
+			return result, err
+		}
+		if !oldest.Valid {
+			return result, nil
+		}
+		day := oldest.Time.UTC().Truncate(24 * time.Hour)
+
+		downsampled, deleted, err := compactObservationDay(ctx, day, day.Add(24*time.Hour))
+		if err != nil {
+			return result, err
+		}
+		result.Downsampled += downsampled
+		result.Deleted += deleted
+	}
+}
+
+func compactObservationDay(ctx context.Context, start, end time.Time) (downsampled, deleted int64, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	for _, g := range rollupGranularities {
+		if _, err := tx.Exec(
+			`DELETE FROM weather_rollups WHERE granularity = $1 AND bucket_start >= $2 AND bucket_start < $3`,
+			g.Name, start, end,
+		); err != nil {
+			return 0, 0, err
+		}
+		res, err := tx.Exec(
+			`INSERT INTO weather_rollups (station_id, granularity, bucket_start, metric, count, sum, min, max) `+
+				rollupSelect(g, "observed_at >= $1 AND observed_at < $2"),
+			start, end,
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+		n, _ := res.RowsAffected()
+		downsampled += n
+	}
+
+	res, err := tx.Exec(`DELETE FROM observations WHERE observed_at >= $1 AND observed_at < $2`, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	deleted, _ = res.RowsAffected()
+	return downsampled, deleted, tx.Commit()
+}
+
+// pruneRollups deletes rollups of granularity name before the cutoff, first
+// merging them into the coarser granularity into wherever it has no bucket yet.
+func pruneRollups(name, into string) func(context.Context, time.Time) (retentionResult, error) {
+	return func(ctx context.Context, cutoff time.Time) (retentionResult, error) {
+		var result retentionResult
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return result, err
+		}
+		defer tx.Rollback()
+
+		if g, ok := rollupNamed(into); ok {
+			res, err := tx.Exec(fmt.Sprintf(
+				`INSERT INTO weather_rollups (station_id, granularity, bucket_start, metric, count, sum, min, max)
+				SELECT station_id, $1, date_trunc('%s', bucket_start), metric, SUM(count), SUM(sum), MIN(min), MAX(max)
+				FROM weather_rollups
+				WHERE granularity = $2 AND bucket_start < $3
+				GROUP BY station_id, date_trunc('%s', bucket_start), metric
+				ON CONFLICT (station_id, granularity, bucket_start, metric) DO NOTHING`, g.Trunc, g.Trunc),
+				g.Name, name, cutoff,
+			)
+			if err != nil {
+				return result, err
+			}
+			result.Downsampled, _ = res.RowsAffected()
+		}
+
+		res, err := tx.Exec(`DELETE FROM weather_rollups WHERE granularity = $1 AND bucket_start < $2`, name, cutoff)
+		if err != nil {
+			return result, err
+		}
+		result.Deleted, _ = res.RowsAffected()
+		return result, tx.Commit()
+	}
+}
+
+// pruneRows runs a delete that has nothing worth downsampling.
+func pruneRows(query string) func(context.Context, time.Time) (retentionResult, error) {
+	return func(ctx context.Context, cutoff time.Time) (retentionResult, error) {
+		var result retentionResult
+		res, err := db.ExecContext(ctx, query, cutoff)
+		if err != nil {
+			return result, err
+		}
+		result.Deleted, _ = res.RowsAffected()
+		return result, nil
+	}
+}
+
+// runRetention performs a compaction run when one is due, or regardless when
+// force is set. A session advisory lock keeps the run to one replica at a
+// time; the others return errRetentionBusy. It returns the ID of the run, or
+// 0 when none was due.
+func runRetention(ctx context.Context, force bool) (int, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext('weather-retention'))`).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, errRetentionBusy
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext('weather-retention'))`)
+
+	// Holding the lock, no run is in progress: one left unfinished died
+	// with its instance and would otherwise show as running.
+	if _, err := conn.ExecContext(ctx,
+		`UPDATE retention_runs SET finished_at = $1, error = $2 WHERE finished_at IS NULL`,
+		time.Now().UTC(), "abandoned: the instance stopped before the run finished",
+	); err != nil {
+		return 0, err
+	}
+
+	if !force {
+		var last sql.NullTime
+		if err := conn.QueryRowContext(ctx, `SELECT MAX(started_at) FROM retention_runs`).Scan(&last); err != nil {
+			return 0, err
+		}
+		if last.Valid && time.Since(last.Time) < retentionInterval {
+			return 0, nil
+		}
+	}
+
+	started := time.Now().UTC()
+	var runID int
+	if err := conn.QueryRowContext(ctx,
+		`INSERT INTO retention_runs (instance, started_at) VALUES ($1, $2) RETURNING id`, serviceID, started,
+	).Scan(&runID); err != nil {
+		return 0, err
+	}
+
+	results := map[string]retentionResult{}
+	runErr := ""
+	policies, err := loadRetentionPolicies()
+	if err != nil {
+		runErr = "failed to load policies: " + err.Error()
+	}
+	for _, configured := range policies {
+		if configured.Cutoff == nil {
+			continue
+		}
+		p, _ := retentionPolicyByName(configured.Name)
+		result, err := p.apply(ctx, *configured.Cutoff)
+		if err != nil {
+			slog.Error("Retention policy failed", "policy", p.Name, "error", err)
+			result.Error = err.Error()
+			runErr = "one or more policies failed"
+		}
+		results[p.Name] = result
+	}
+
+	encoded, _ := json.Marshal(results)
+	_, err = conn.ExecContext(context.Background(),
+		`UPDATE retention_runs SET finished_at = $1, results = $2, error = $3 WHERE id = $4`,
+		time.Now().UTC(), encoded, runErr, runID,
+	)
+	return runID, err
+}
+
+// retentionCutoff is the start of the UTC day keepDays before now.
+func retentionCutoff(now time.Time, keepDays int) time.Time {
+	return now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -keepDays)
+}
+
+// runRetentionLoop checks every few minutes whether a compaction run is due.
+// Every replica runs the loop; the lock in runRetention lets one of them work.
+func runRetentionLoop() {
+	ticker := time.NewTicker(retentionCheckEvery)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), retentionRunTimeout)
+		if _, err := runRetention(ctx, false); err != nil && err != errRetentionBusy {
+			slog.Error("Retention run failed", "error", err)
+		}
+		cancel()
+		<-ticker.C
+	}
+}
+
+// RetentionRun is a past or ongoing compaction run.
+type RetentionRun struct {
+	ID          int                        `json:"id"`
+	Instance    string                     `json:"instance"`
+	StartedAt   time.Time                  `json:"started_at"`
+	FinishedAt  *time.Time                 `json:"finished_at,omitempty"`
+	Deleted     int64                      `json:"rows_deleted"`
+	Downsampled int64                      `json:"rows_downsampled"`
+	Results     map[string]retentionResult `json:"policies"`
+	Error       string                     `json:"error,omitempty"`
+}
+
+// RetentionPolicy is a policy as configured in the database.
+type RetentionPolicy struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	KeepDays    int        `json:"keep_days"`
+	Enabled     bool       `json:"enabled"`
+	Cutoff      *time.Time `json:"cutoff,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func loadRetentionRun(query string, args ...any) (*RetentionRun, error) {
+	var run RetentionRun
+	var results []byte
+	err := db.QueryRow(`SELECT id, instance, started_at, finished_at, results, error FROM retention_runs `+query, args...).
+		Scan(&run.ID, &run.Instance, &run.StartedAt, &run.FinishedAt, &results, &run.Error)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(results, &run.Results); err != nil {
+		return nil, err
+	}
+	for _, r := range run.Results {
+		run.Deleted += r.Deleted
+		run.Downsampled += r.Downsampled
+	}
+	return &run, nil
+}
+
+func loadRetentionPolicies() ([]RetentionPolicy, error) {
+	rows, err := db.Query(`SELECT name, keep_days, enabled, updated_at FROM retention_policies`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	configured := map[string]RetentionPolicy{}
+	for rows.Next() {
+		var p RetentionPolicy
+		if err := rows.Scan(&p.Name, &p.KeepDays, &p.Enabled, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		configured[p.Name] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	policies := []RetentionPolicy{}
+	for _, def := range retentionPolicies {
+		p, ok := configured[def.Name]
+		if !ok {
+			continue
+		}
+		p.Description = def.Description
+		if p.Enabled && p.KeepDays > 0 {
+			cutoff := retentionCutoff(now, p.KeepDays)
+			p.Cutoff = &cutoff
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// RetentionStatus is the response of GET /retention/status.
+type RetentionStatus struct {
+	Interval string            `json:"interval"`
+	LastRun  *RetentionRun     `json:"last_run"`
+	Running  *RetentionRun     `json:"running,omitempty"`
+	NextRun  time.Time         `json:"next_run"`
+	Policies []RetentionPolicy `json:"policies"`
+}
+
+// retentionStatus reports the last completed run, any run in progress, when
+// the next run is due and the configured policies.
+func retentionStatus(w http.ResponseWriter, r *http.Request) {
+	lastRun, err := loadRetentionRun(`WHERE finished_at IS NOT NULL ORDER BY started_at DESC LIMIT 1`)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, "Failed to query retention runs", http.StatusInternalServerError)
+		return
+	}
+	// A run that died with its instance stays unfinished only until the next
+	// instance takes the lock and marks it abandoned.
+	running, err := loadRetentionRun(`WHERE finished_at IS NULL ORDER BY started_at DESC LIMIT 1`)
+	if err != nil && err != sql.ErrNoRows {
+		http.Error(w, "Failed to query retention runs", http.StatusInternalServerError)
+		return
+	}
+	policies, err := loadRetentionPolicies()
+	if err != nil {
+		http.Error(w, "Failed to query retention policies", http.StatusInternalServerError)
+		return
+	}
+
+	nextRun := time.Now().UTC()
+	if last := latestRun(lastRun, running); last != nil && last.StartedAt.Add(retentionInterval).After(nextRun) {
+		nextRun = last.StartedAt.Add(retentionInterval)
+	}
+
+	response := RetentionStatus{retentionInterval.String(), lastRun, running, nextRun, policies}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func latestRun(runs ...*RetentionRun) *RetentionRun {
+	var latest *RetentionRun
+	for _, run := range runs {
+		if run != nil && (latest == nil || run.StartedAt.After(latest.StartedAt)) {
+			latest = run
+		}
+	}
+	return latest
+}
+
+func listRetentionPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := loadRetentionPolicies()
+	if err != nil {
+		http.Error(w, "Failed to query retention policies", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// updateRetentionPolicy changes how many days a policy keeps and whether it
+// runs at all. keep_days 0 keeps everything.
+func updateRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if _, ok := retentionPolicyByName(name); !ok {
+		http.Error(w, "Retention policy not found", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		KeepDays *int  `json:"keep_days"`
+		Enabled  *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.KeepDays != nil && *req.KeepDays < 0 {
+		http.Error(w, "keep_days must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	_, err := db.ExecContext(r.Context(),
+		`UPDATE retention_policies SET keep_days = COALESCE($1, keep_days), enabled = COALESCE($2, enabled),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE name = $3`,
+		req.KeepDays, req.Enabled, name,
+	)
+	if err != nil {
+		http.Error(w, "Failed to update retention policy", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Retention policy %s updated", name)
+}
+
+// triggerRetention runs compaction now on this instance instead of waiting
+// for the next scheduled run.
+func triggerRetention(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), retentionRunTimeout)
+	defer cancel()
+
+	runID, err := runRetention(ctx, true)
+	if err == errRetentionBusy {
+		http.Error(w, "A retention run is already in progress", http.StatusConflict)
+		return
+	}
+	if err != nil && runID == 0 {
+		http.Error(w, "Failed to run retention", http.StatusInternalServerError)
+		return
+	}
+
+	run, err := loadRetentionRun(`WHERE id = $1`, runID)
+	if err != nil {
+		http.Error(w, "Failed to query retention run", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
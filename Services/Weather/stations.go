@@ -0,0 +1,760 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Station is a named weather station at a fixed position.
+type Station struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Latitude  *float64  `json:"latitude,omitempty"`
+	Longitude *float64  `json:"longitude,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Measurement is a single metric reading together with its unit and quality flag.
+type Measurement struct {
+	Value   float64 `json:"value"`
+	Unit    string  `json:"unit"`
+	Quality string  `json:"quality"`
+}
+
+// Observation is a multi-metric report from a station at a point in time.
+// Metrics the station did not report are left nil.
+type Observation struct {
+	ID            int          `json:"id"`
+	StationID     string       `json:"station_id"`
+	ObservedAt    time.Time    `json:"observed_at"`
+	Description   string       `json:"description"`
+	Temperature   *Measurement `json:"temperature,omitempty"`
+	Humidity      *Measurement `json:"humidity,omitempty"`
+	Pressure      *Measurement `json:"pressure,omitempty"`
+	WindSpeed     *Measurement `json:"wind_speed,omitempty"`
+	WindDirection *Measurement `json:"wind_direction,omitempty"`
+	Precipitation *Measurement `json:"precipitation,omitempty"`
+	Visibility    *Measurement `json:"visibility,omitempty"`
+}
+
+// observationMetric describes how a metric is stored: its column name (also
+// used as JSON key) and the canonical unit values are kept in.
+type observationMetric struct {
+	Name string
+	Unit string
+}
+
+// observationMetrics lists the metrics in the same order as Observation.measurements.
+var observationMetrics = []observationMetric{
+	{"temperature", "°C"},
+	{"humidity", "%"},
+	{"pressure", "hPa"},
+	{"wind_speed", "km/h"},
+	{"wind_direction", "°"},
+	{"precipitation", "mm"},
+	{"visibility", "km"},
+}
+
+// Quality flags a measurement can carry.
+const (
+	qualityGood      = "good"
+	qualityEstimated = "estimated"
+	qualitySuspect   = "suspect"
+	qualityBad       = "bad"
+)
+
+// trustedQualities are the quality flags whose values feed rollups,
+// aggregates and alert rules. Suspect and bad readings are kept but ignored.
+var trustedQualities = []string{qualityGood, qualityEstimated}
+
+func trustedQuality(quality string) bool {
+	for _, q := range trustedQualities {
+		if q == quality {
+			return true
+		}
+	}
+	return false
+}
+
+func validQuality(q string) bool {
+	switch q {
+	case qualityGood, qualityEstimated, qualitySuspect, qualityBad:
+		return true
+	}
+	return false
+}
+
+// measurements returns pointers to the metric fields in observationMetrics order.
+func (o *Observation) measurements() []**Measurement {
+	return []**Measurement{
+		&o.Temperature,
+		&o.Humidity,
+		&o.Pressure,
+		&o.WindSpeed,
+		&o.WindDirection,
+		&o.Precipitation,
+		&o.Visibility,
+	}
+}
+
+// normalize converts values to their canonical units, which are assumed when
+// no unit is given, and fills in default quality flags. ObservedAt becomes
+// UTC, as observed_at has no time zone and would drop the offset.
+func (o *Observation) normalize() error {
+	o.ObservedAt = o.ObservedAt.UTC()
+	for i, m := range o.measurements() {
+		if *m == nil {
+			continue
+		}
+		metric := observationMetrics[i]
+		if (*m).Unit != "" && (*m).Unit != metric.Unit {
+			value, err := toCanonical(metric.Name, (*m).Unit, (*m).Value)
+			if err != nil {
+				return err
+			}
+			(*m).Value = value
+		}
+		(*m).Unit = metric.Unit
+		if (*m).Quality == "" {
+			(*m).Quality = qualityGood
+		}
+		if !validQuality((*m).Quality) {
+			return fmt.Errorf("invalid quality flag %q for %s", (*m).Quality, metric.Name)
+		}
+	}
+	return nil
+}
+
+// observationColumns is the column list shared by every observation query.
+func observationColumns() string {
+	cols := []string{"id", "station_id", "observed_at", "description"}
+	for _, m := range observationMetrics {
+		cols = append(cols, m.Name, m.Name+"_quality")
+	}
+	return strings.Join(cols, ", ")
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanObservation(row rowScanner) (Observation, error) {
+	var o Observation
+	values := make([]sql.NullFloat64, len(observationMetrics))
+	qualities := make([]sql.NullString, len(observationMetrics))
+
+	dest := []any{&o.ID, &o.StationID, &o.ObservedAt, &o.Description}
+	for i := range observationMetrics {
+		dest = append(dest, &values[i], &qualities[i])
+	}
+	if err := row.Scan(dest...); err != nil {
+		return o, err
+	}
+
+	for i, m := range o.measurements() {
+		if !values[i].Valid {
+			continue
+		}
+		*m = &Measurement{
+			Value:   values[i].Float64,
+			Unit:    observationMetrics[i].Unit,
+			Quality: qualities[i].String,
+		}
+	}
+	return o, nil
+}
+
+// insertObservation screens a live observation for anomalies, stores it and
+// evaluates alert rules against it. Every live write path (the station API and
+// the legacy /weather endpoints) goes through here.
+func insertObservation(tx *sql.Tx, o *Observation) error {
+	anomalies, restored, err := detectAnomalies(tx, o)
+	if err != nil {
+		return err
+	}
+	if err := storeObservation(tx, o); err != nil {
+		return err
+	}
+	if err := recordAnomalies(tx, o, anomalies); err != nil {
+		return err
+	}
+	if err := evaluateAlertRules(tx, o); err != nil {
+		return err
+	}
+	for _, id := range restored {
+		if err := publishObservationUpdate(tx, id); err != nil {
+			return err
+		}
+	}
+	return publishEvent(tx, "observation", "created", strconv.Itoa(o.ID), o)
+}
+
+// publishObservationUpdate publishes the stored state of an observation that
+// was changed in tx.
+func publishObservationUpdate(tx *sql.Tx, id int) error {
+	o, err := scanObservation(tx.QueryRow(`SELECT `+observationColumns()+` FROM observations WHERE id = $1`, id))
+	if err != nil {
+		return err
+	}
+	return publishEvent(tx, "observation", "updated", strconv.Itoa(id), o)
+}
+
+// storeObservation stores o, sets its ID and ObservedAt and updates the
+// rollups. It returns errConflict when the station already has an
+// observation at that time. The importer uses it directly so archived data does not raise
+// alerts; it publishes the event itself.
+func storeObservation(tx *sql.Tx, o *Observation) error {
+	cols := []string{"station_id", "observed_at", "description"}
+	observedAt := o.ObservedAt.UTC()
+	if observedAt.IsZero() {
+		observedAt = time.Now().UTC()
+	}
+	args := []any{o.StationID, observedAt, o.Description}
+
+	for i, m := range o.measurements() {
+		if *m == nil {
+			continue
+		}
+		cols = append(cols, observationMetrics[i].Name, observationMetrics[i].Name+"_quality")
+		args = append(args, (*m).Value, (*m).Quality)
+	}
+
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO observations (%s) VALUES (%s)
+		ON CONFLICT (station_id, observed_at) DO NOTHING
+		RETURNING id, observed_at`,
+		strings.Join(cols, ", "), strings.Join(placeholders, ", "),
+	)
+	err := tx.QueryRow(query, args...).Scan(&o.ID, &o.ObservedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("observation of station %s at %s %w", o.StationID, observedAt.Format(time.RFC3339), errConflict)
+	}
+	if err != nil {
+		return err
+	}
+	return addToRollups(tx, o)
+}
+
+// stationIDFor derives a station ID from a free-text location, the same way
+// the schema migration does for legacy weather rows.
+func stationIDFor(location string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(location) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	if b.Len() == 0 {
+		return "unknown"
+	}
+	return b.String()
+}
+
+// ensureStation makes sure a station exists for a legacy location name and
+// returns its ID.
+func ensureStation(tx *sql.Tx, location string) (string, error) {
+	id := stationIDFor(location)
+	_, err := tx.Exec(`INSERT INTO stations (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, id, location)
+	return id, err
+}
+
+// resolveStation maps a location, given as either a station ID or a station
+// name, to the station ID.
+func resolveStation(location string) (string, error) {
+	var id string
+	err := db.QueryRow(
+		`SELECT id FROM stations WHERE id = $1 OR name = $1 ORDER BY (id = $1) DESC, id LIMIT 1`,
+		location,
+	).Scan(&id)
+	return id, err
+}
+
+// The storage functions below are shared by the REST handlers and the gRPC
+// server, so both validate input the same way and publish the same events.
+
+const stationColumns = `id, name, latitude, longitude, created_at`
+
+func scanStation(row rowScanner) (Station, error) {
+	var station Station
+	err := row.Scan(&station.ID, &station.Name, &station.Latitude, &station.Longitude, &station.CreatedAt)
+	return station, err
+}
+
+// createStation stores a new station and publishes its created event. It
+// returns errConflict when the ID is taken.
+func createStation(ctx context.Context, station Station) (Station, error) {
+	if station.ID == "" || station.Name == "" {
+		return station, invalidInputError("Station id and name are required")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return station, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO stations (id, name, latitude, longitude) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO NOTHING
+		RETURNING created_at`,
+		station.ID, station.Name, station.Latitude, station.Longitude,
+	).Scan(&station.CreatedAt)
+	if err == sql.ErrNoRows {
+		return station, fmt.Errorf("station %s %w", station.ID, errConflict)
+	}
+	if err != nil {
+		return station, err
+	}
+	if err := publishEvent(tx, "station", "created", station.ID, station); err != nil {
+		return station, err
+	}
+	return station, tx.Commit()
+}
+
+// loadStation returns errNotFound for a station that does not exist.
+func loadStation(ctx context.Context, id string) (Station, error) {
+	station, err := scanStation(db.QueryRowContext(ctx, `SELECT `+stationColumns+` FROM stations WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return station, errNotFound
+	}
+	return station, err
+}
+
+// saveStation replaces the name and position of a station and publishes its
+// updated event.
+func saveStation(ctx context.Context, id, name string, latitude, longitude *float64) (Station, error) {
+	if name == "" {
+		return Station{}, invalidInputError("Station name is required")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Station{}, err
+	}
+	defer tx.Rollback()
+
+	station, err := scanStation(tx.QueryRowContext(ctx,
+		`UPDATE stations SET name = $1, latitude = $2, longitude = $3 WHERE id = $4
+		RETURNING `+stationColumns,
+		name, latitude, longitude, id,
+	))
+	if err == sql.ErrNoRows {
+		return station, errNotFound
+	}
+	if err != nil {
+		return station, err
+	}
+	if err := publishEvent(tx, "station", "updated", id, station); err != nil {
+		return station, err
+	}
+	return station, tx.Commit()
+}
+
+// removeStation deletes a station and its observations. Deleting a station
+// that does not exist is not an error.
+func removeStation(ctx context.Context, id string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Deleting a station cascades to its observations; subscribers get the
+	// single station event rather than one per observation.
+	result, err := tx.ExecContext(ctx, `DELETE FROM stations WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		if err := publishEvent(tx, "station", "deleted", id, nil); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func loadStations(ctx context.Context) ([]Station, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+stationColumns+` FROM stations ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stations := []Station{}
+	for rows.Next() {
+		station, err := scanStation(rows)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, station)
+	}
+	return stations, rows.Err()
+}
+
+// createObservation normalizes and stores an observation from a station,
+// through insertObservation. It returns errNotFound when the station does not
+// exist.
+func createObservation(ctx context.Context, o *Observation) error {
+	if err := o.normalize(); err != nil {
+		return invalidInputError(err.Error())
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM stations WHERE id = $1)`, o.StationID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return errNotFound
+	}
+	if err := insertObservation(tx, o); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// correctObservation replaces the temperature and description of an
+// observation. The corrected temperature is trusted, so it counts in rollups
+// and alert rules, and any pending review of the reading it replaces is
+// closed so the review cannot overwrite it. It returns errNotFound when the
+// observation does not exist.
+func correctObservation(ctx context.Context, id int, temperature Measurement, description string) (Observation, error) {
+	if temperature.Unit != "" && temperature.Unit != observationMetrics[metricIndex("temperature")].Unit {
+		value, err := toCanonical("temperature", temperature.Unit, temperature.Value)
+		if err != nil {
+			return Observation{}, invalidInputError(err.Error())
+		}
+		temperature.Value = value
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Observation{}, err
+	}
+	defer tx.Rollback()
+
+	o, err := scanObservation(tx.QueryRowContext(ctx,
+		`UPDATE observations SET temperature = $1, temperature_quality = $2, description = $3
+		WHERE id = $4 RETURNING `+observationColumns(),
+		temperature.Value, qualityGood, description, id,
+	))
+	if err == sql.ErrNoRows {
+		return o, errNotFound
+	}
+	if err != nil {
+		return o, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE weather_anomalies SET status = $1, reviewed_at = CURRENT_TIMESTAMP
+		WHERE observation_id = $2 AND metric = 'temperature' AND status = $3`,
+		anomalyRejected, id, anomalyPending,
+	); err != nil {
+		return o, err
+	}
+	if err := refreshRollups(tx, o.StationID, o.ObservedAt); err != nil {
+		return o, err
+	}
+	if err := publishObservationUpdate(tx, id); err != nil {
+		return o, err
+	}
+	return o, tx.Commit()
+}
+
+// loadObservation returns errNotFound for an observation that does not exist.
+// Its measurements are in canonical units.
+func loadObservation(ctx context.Context, id int) (Observation, error) {
+	o, err := scanObservation(db.QueryRowContext(ctx, `SELECT `+observationColumns()+` FROM observations WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return o, errNotFound
+	}
+	return o, err
+}
+
+// loadStationObservations returns up to limit observations of a station made
+// from from until to, newest first, in canonical units.
+func loadStationObservations(ctx context.Context, stationID string, from, to time.Time, limit int) ([]Observation, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+observationColumns()+` FROM observations
+		WHERE station_id = $1 AND observed_at >= $2 AND observed_at < $3
+		ORDER BY observed_at DESC LIMIT $4`,
+		stationID, from, to, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	observations := []Observation{}
+	for rows.Next() {
+		o, err := scanObservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		observations = append(observations, o)
+	}
+	return observations, rows.Err()
+}
+
+// deleteObservationByID removes an observation and recomputes the rollups it
+// contributed to. Deleting a missing observation is not an error.
+func deleteObservationByID(ctx context.Context, id string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stationID string
+	var observedAt time.Time
+	err = tx.QueryRowContext(ctx, `DELETE FROM observations WHERE id = $1 RETURNING station_id, observed_at`, id).Scan(&stationID, &observedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := refreshRollups(tx, stationID, observedAt); err != nil {
+		return err
+	}
+	if err := publishEvent(tx, "observation", "deleted", id, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func addStation(w http.ResponseWriter, r *http.Request) {
+	var input Station
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	station, err := createStation(r.Context(), input)
+	var invalid invalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, errConflict):
+		http.Error(w, "Station already exists", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to add station", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", "/stations/"+url.PathEscape(station.ID))
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "Station added with ID: %s", station.ID)
+}
+
+func getStation(w http.ResponseWriter, r *http.Request) {
+	station, err := loadStation(r.Context(), chi.URLParam(r, "id"))
+	if err == errNotFound {
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get station", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(station)
+}
+
+func updateStation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var input struct {
+		Name      string   `json:"name"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	_, err := saveStation(r.Context(), id, input.Name, input.Latitude, input.Longitude)
+	var invalid invalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err == errNotFound:
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Failed to update station", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Station %s updated", id)
+}
+
+func deleteStation(w http.ResponseWriter, r *http.Request) {
+	if err := removeStation(r.Context(), chi.URLParam(r, "id")); err != nil {
+		http.Error(w, "Failed to delete station", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("Station deleted"))
+}
+
+func listStations(w http.ResponseWriter, r *http.Request) {
+	stations, err := loadStations(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to query stations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stations)
+}
+
+func addObservation(w http.ResponseWriter, r *http.Request) {
+	var input Observation
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+	input.StationID = chi.URLParam(r, "id")
+
+	err := createObservation(r.Context(), &input)
+	var invalid invalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err == errNotFound:
+		http.Error(w, "Station not found", http.StatusNotFound)
+		return
+	case errors.Is(err, errConflict):
+		http.Error(w, "Station already has an observation at that time", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to add observation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/observations/%d", input.ID))
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "Observation added with ID: %d", input.ID)
+}
+
+// defaultObservationLimit caps the observations listed when no limit is
+// given.
+const defaultObservationLimit = 100
+
+// listStationObservations returns a station's observations, newest first.
+// Optional from/to (RFC 3339) narrow the time range and limit caps the count.
+func listStationObservations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, to, err := parseTimeRange(query.Get("from"), query.Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := defaultObservationLimit
+	if s := query.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	observations, err := loadStationObservations(r.Context(), chi.URLParam(r, "id"), from, to, limit)
+	if err != nil {
+		http.Error(w, "Failed to query observations", http.StatusInternalServerError)
+		return
+	}
+	units := requestUnits(r)
+	for i := range observations {
+		observations[i].convertTo(units)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(observations)
+}
+
+func getObservation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Observation not found", http.StatusNotFound)
+		return
+	}
+	o, err := loadObservation(r.Context(), id)
+	if err == errNotFound {
+		http.Error(w, "Observation not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to get observation", http.StatusInternalServerError)
+		return
+	}
+	o.convertTo(requestUnits(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(o)
+}
+
+func deleteObservation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := deleteObservationByID(r.Context(), id); err != nil {
+		http.Error(w, "Failed to delete observation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("Observation deleted"))
+}
+
+// parseTimeRange parses optional RFC 3339 bounds. A missing from means the
+// beginning of time and a missing to means now. Both are returned in UTC.
+func parseTimeRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	from := time.Unix(0, 0).UTC()
+	to := time.Now().UTC()
+	var err error
+	if fromStr != "" {
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			return from, to, errors.New("Invalid from, expected RFC 3339 timestamp")
+		}
+	}
+	if toStr != "" {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			return from, to, errors.New("Invalid to, expected RFC 3339 timestamp")
+		}
+	}
+	from, to = from.UTC(), to.UTC()
+	if !from.Before(to) {
+		return from, to, errors.New("from must be before to")
+	}
+	return from, to, nil
+}
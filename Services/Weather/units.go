@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// unitConversion converts a canonical value v to v*Scale + Offset in Unit.
+type unitConversion struct {
+	Unit   string
+	Scale  float64
+	Offset float64
+}
+
+func (c unitConversion) from(canonical float64) float64 {
+	return math.Round((canonical*c.Scale+c.Offset)*100) / 100
+}
+
+func (c unitConversion) to(value float64) float64 {
+	return (value - c.Offset) / c.Scale
+}
+
+const (
+	unitsMetric   = "metric"
+	unitsImperial = "imperial"
+	unitsSI       = "si"
+)
+
+// unitSystems lists, per unit system, the metrics shown in a unit other than
+// the canonical one. Metrics that are not listed keep their canonical unit.
+var unitSystems = map[string]map[string]unitConversion{
+	unitsMetric: {},
+	unitsImperial: {
+		"temperature":   {"°F", 1.8, 32},
+		"wind_speed":    {"mph", 1 / 1.609344, 0},
+		"precipitation": {"in", 1 / 25.4, 0},
+	},
+	unitsSI: {
+		"temperature": {"K", 1, 273.15},
+		"wind_speed":  {"m/s", 1 / 3.6, 0},
+	},
+}
+
+// conversion returns how metric is converted in the given unit system.
+func conversion(system, metric string) unitConversion {
+	if c, ok := unitSystems[system][metric]; ok {
+		return c
+	}
+	return unitConversion{Unit: observationMetrics[metricIndex(metric)].Unit, Scale: 1}
+}
+
+// toCanonical converts a value reported in unit to the canonical unit of metric.
+func toCanonical(metric, unit string, value float64) (float64, error) {
+	for _, system := range []string{unitsMetric, unitsImperial, unitsSI} {
+		if c := conversion(system, metric); c.Unit == unit {
+			return c.to(value), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported unit %q for %s", unit, metric)
+}
+
+// negotiateUnits picks the unit system for a request from the units query
+// parameter or, failing that, a units parameter on the Accept header such as
+// "application/json; units=imperial". It defaults to metric.
+func negotiateUnits(r *http.Request) (string, error) {
+	system := r.URL.Query().Get("units")
+	if system == "" {
+		for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+			_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+			if err == nil && params["units"] != "" {
+				system = params["units"]
+				break
+			}
+		}
+	}
+	if system == "" {
+		return unitsMetric, nil
+	}
+	system = strings.ToLower(system)
+	if _, ok := unitSystems[system]; !ok {
+		return "", fmt.Errorf("Unknown units %q, expected metric, imperial or si", system)
+	}
+	return system, nil
+}
+
+// unitsMiddleware negotiates the unit system and rejects unknown ones before
+// the handler runs.
+func unitsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		system, err := negotiateUnits(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Units", system)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestUnits returns the unit system negotiated by unitsMiddleware.
+func requestUnits(r *http.Request) string {
+	system, err := negotiateUnits(r)
+	if err != nil {
+		return unitsMetric
+	}
+	return system
+}
+
+// convertTo rewrites every measurement of o into the given unit system.
+func (o *Observation) convertTo(system string) {
+	for i, m := range o.measurements() {
+		if *m == nil {
+			continue
+		}
+		c := conversion(system, observationMetrics[i].Name)
+		(*m).Value = c.from((*m).Value)
+		(*m).Unit = c.Unit
+	}
+}
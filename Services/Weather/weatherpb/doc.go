@@ -0,0 +1,5 @@
+// Package weatherpb holds the gRPC API of the Weather service, generated from
+// weather.proto.
+package weatherpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative weather.proto
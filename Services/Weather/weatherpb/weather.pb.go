@@ -0,0 +1,1435 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: weather.proto
+
+package weatherpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Units is the unit system measurements are returned in, as negotiated with
+// ?units over REST. Unspecified means metric.
+type Units int32
+
+const (
+	Units_UNITS_UNSPECIFIED Units = 0
+	Units_UNITS_METRIC      Units = 1
+	Units_UNITS_IMPERIAL    Units = 2
+	Units_UNITS_SI          Units = 3
+)
+
+// Enum value maps for Units.
+var (
+	Units_name = map[int32]string{
+		0: "UNITS_UNSPECIFIED",
+		1: "UNITS_METRIC",
+		2: "UNITS_IMPERIAL",
+		3: "UNITS_SI",
+	}
+	Units_value = map[string]int32{
+		"UNITS_UNSPECIFIED": 0,
+		"UNITS_METRIC":      1,
+		"UNITS_IMPERIAL":    2,
+		"UNITS_SI":          3,
+	}
+)
+
+func (x Units) Enum() *Units {
+	p := new(Units)
+	*p = x
+	return p
+}
+
+func (x Units) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Units) Descriptor() protoreflect.EnumDescriptor {
+	return file_weather_proto_enumTypes[0].Descriptor()
+}
+
+func (Units) Type() protoreflect.EnumType {
+	return &file_weather_proto_enumTypes[0]
+}
+
+func (x Units) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Units.Descriptor instead.
+func (Units) EnumDescriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{0}
+}
+
+// Quality flags a measurement. Only good and estimated values feed rollups,
+// aggregates and alert rules. Unspecified means good on input.
+type Quality int32
+
+const (
+	Quality_QUALITY_UNSPECIFIED Quality = 0
+	Quality_QUALITY_GOOD        Quality = 1
+	Quality_QUALITY_ESTIMATED   Quality = 2
+	Quality_QUALITY_SUSPECT     Quality = 3
+	Quality_QUALITY_BAD         Quality = 4
+)
+
+// Enum value maps for Quality.
+var (
+	Quality_name = map[int32]string{
+		0: "QUALITY_UNSPECIFIED",
+		1: "QUALITY_GOOD",
+		2: "QUALITY_ESTIMATED",
+		3: "QUALITY_SUSPECT",
+		4: "QUALITY_BAD",
+	}
+	Quality_value = map[string]int32{
+		"QUALITY_UNSPECIFIED": 0,
+		"QUALITY_GOOD":        1,
+		"QUALITY_ESTIMATED":   2,
+		"QUALITY_SUSPECT":     3,
+		"QUALITY_BAD":         4,
+	}
+)
+
+func (x Quality) Enum() *Quality {
+	p := new(Quality)
+	*p = x
+	return p
+}
+
+func (x Quality) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Quality) Descriptor() protoreflect.EnumDescriptor {
+	return file_weather_proto_enumTypes[1].Descriptor()
+}
+
+func (Quality) Type() protoreflect.EnumType {
+	return &file_weather_proto_enumTypes[1]
+}
+
+func (x Quality) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Quality.Descriptor instead.
+func (Quality) EnumDescriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{1}
+}
+
+type Action int32
+
+const (
+	Action_ACTION_UNSPECIFIED Action = 0
+	Action_ACTION_CREATED     Action = 1
+	Action_ACTION_UPDATED     Action = 2
+	Action_ACTION_DELETED     Action = 3
+)
+
+// Enum value maps for Action.
+var (
+	Action_name = map[int32]string{
+		0: "ACTION_UNSPECIFIED",
+		1: "ACTION_CREATED",
+		2: "ACTION_UPDATED",
+		3: "ACTION_DELETED",
+	}
+	Action_value = map[string]int32{
+		"ACTION_UNSPECIFIED": 0,
+		"ACTION_CREATED":     1,
+		"ACTION_UPDATED":     2,
+		"ACTION_DELETED":     3,
+	}
+)
+
+func (x Action) Enum() *Action {
+	p := new(Action)
+	*p = x
+	return p
+}
+
+func (x Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_weather_proto_enumTypes[2].Descriptor()
+}
+
+func (Action) Type() protoreflect.EnumType {
+	return &file_weather_proto_enumTypes[2]
+}
+
+func (x Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Action.Descriptor instead.
+func (Action) EnumDescriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{2}
+}
+
+type Station struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Latitude      *float64               `protobuf:"fixed64,3,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude     *float64               `protobuf:"fixed64,4,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Station) Reset() {
+	*x = Station{}
+	mi := &file_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Station) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Station) ProtoMessage() {}
+
+func (x *Station) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Station.ProtoReflect.Descriptor instead.
+func (*Station) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Station) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Station) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Station) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *Station) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *Station) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Measurement struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Value float64                `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	// On input, the unit the value is in; the metric's canonical unit when
+	// empty.
+	Unit          string  `protobuf:"bytes,2,opt,name=unit,proto3" json:"unit,omitempty"`
+	Quality       Quality `protobuf:"varint,3,opt,name=quality,proto3,enum=metagrid.weather.v1.Quality" json:"quality,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Measurement) Reset() {
+	*x = Measurement{}
+	mi := &file_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Measurement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Measurement) ProtoMessage() {}
+
+func (x *Measurement) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Measurement.ProtoReflect.Descriptor instead.
+func (*Measurement) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Measurement) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Measurement) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Measurement) GetQuality() Quality {
+	if x != nil {
+		return x.Quality
+	}
+	return Quality_QUALITY_UNSPECIFIED
+}
+
+// Observation is a report from a station. Metrics the station did not report
+// are unset.
+type Observation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	StationId     string                 `protobuf:"bytes,2,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	ObservedAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=observed_at,json=observedAt,proto3" json:"observed_at,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Temperature   *Measurement           `protobuf:"bytes,5,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Humidity      *Measurement           `protobuf:"bytes,6,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Pressure      *Measurement           `protobuf:"bytes,7,opt,name=pressure,proto3" json:"pressure,omitempty"`
+	WindSpeed     *Measurement           `protobuf:"bytes,8,opt,name=wind_speed,json=windSpeed,proto3" json:"wind_speed,omitempty"`
+	WindDirection *Measurement           `protobuf:"bytes,9,opt,name=wind_direction,json=windDirection,proto3" json:"wind_direction,omitempty"`
+	Precipitation *Measurement           `protobuf:"bytes,10,opt,name=precipitation,proto3" json:"precipitation,omitempty"`
+	Visibility    *Measurement           `protobuf:"bytes,11,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Observation) Reset() {
+	*x = Observation{}
+	mi := &file_weather_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Observation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Observation) ProtoMessage() {}
+
+func (x *Observation) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Observation.ProtoReflect.Descriptor instead.
+func (*Observation) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Observation) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Observation) GetStationId() string {
+	if x != nil {
+		return x.StationId
+	}
+	return ""
+}
+
+func (x *Observation) GetObservedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ObservedAt
+	}
+	return nil
+}
+
+func (x *Observation) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Observation) GetTemperature() *Measurement {
+	if x != nil {
+		return x.Temperature
+	}
+	return nil
+}
+
+func (x *Observation) GetHumidity() *Measurement {
+	if x != nil {
+		return x.Humidity
+	}
+	return nil
+}
+
+func (x *Observation) GetPressure() *Measurement {
+	if x != nil {
+		return x.Pressure
+	}
+	return nil
+}
+
+func (x *Observation) GetWindSpeed() *Measurement {
+	if x != nil {
+		return x.WindSpeed
+	}
+	return nil
+}
+
+func (x *Observation) GetWindDirection() *Measurement {
+	if x != nil {
+		return x.WindDirection
+	}
+	return nil
+}
+
+func (x *Observation) GetPrecipitation() *Measurement {
+	if x != nil {
+		return x.Precipitation
+	}
+	return nil
+}
+
+func (x *Observation) GetVisibility() *Measurement {
+	if x != nil {
+		return x.Visibility
+	}
+	return nil
+}
+
+type CreateStationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Station       *Station               `protobuf:"bytes,1,opt,name=station,proto3" json:"station,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateStationRequest) Reset() {
+	*x = CreateStationRequest{}
+	mi := &file_weather_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateStationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateStationRequest) ProtoMessage() {}
+
+func (x *CreateStationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateStationRequest.ProtoReflect.Descriptor instead.
+func (*CreateStationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateStationRequest) GetStation() *Station {
+	if x != nil {
+		return x.Station
+	}
+	return nil
+}
+
+type GetStationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStationRequest) Reset() {
+	*x = GetStationRequest{}
+	mi := &file_weather_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStationRequest) ProtoMessage() {}
+
+func (x *GetStationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStationRequest.ProtoReflect.Descriptor instead.
+func (*GetStationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetStationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UpdateStationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The station to update, by id, with its new name and position.
+	Station       *Station `protobuf:"bytes,1,opt,name=station,proto3" json:"station,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateStationRequest) Reset() {
+	*x = UpdateStationRequest{}
+	mi := &file_weather_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateStationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateStationRequest) ProtoMessage() {}
+
+func (x *UpdateStationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateStationRequest.ProtoReflect.Descriptor instead.
+func (*UpdateStationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateStationRequest) GetStation() *Station {
+	if x != nil {
+		return x.Station
+	}
+	return nil
+}
+
+type DeleteStationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteStationRequest) Reset() {
+	*x = DeleteStationRequest{}
+	mi := &file_weather_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteStationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteStationRequest) ProtoMessage() {}
+
+func (x *DeleteStationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteStationRequest.ProtoReflect.Descriptor instead.
+func (*DeleteStationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteStationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListStationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStationsRequest) Reset() {
+	*x = ListStationsRequest{}
+	mi := &file_weather_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStationsRequest) ProtoMessage() {}
+
+func (x *ListStationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStationsRequest.ProtoReflect.Descriptor instead.
+func (*ListStationsRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{7}
+}
+
+type ListStationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stations      []*Station             `protobuf:"bytes,1,rep,name=stations,proto3" json:"stations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStationsResponse) Reset() {
+	*x = ListStationsResponse{}
+	mi := &file_weather_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStationsResponse) ProtoMessage() {}
+
+func (x *ListStationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStationsResponse.ProtoReflect.Descriptor instead.
+func (*ListStationsResponse) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListStationsResponse) GetStations() []*Station {
+	if x != nil {
+		return x.Stations
+	}
+	return nil
+}
+
+type CreateObservationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The observation's id is ignored; observed_at defaults to now.
+	Observation *Observation `protobuf:"bytes,1,opt,name=observation,proto3" json:"observation,omitempty"`
+	// The unit system the stored observation is returned in.
+	Units         Units `protobuf:"varint,2,opt,name=units,proto3,enum=metagrid.weather.v1.Units" json:"units,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateObservationRequest) Reset() {
+	*x = CreateObservationRequest{}
+	mi := &file_weather_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateObservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateObservationRequest) ProtoMessage() {}
+
+func (x *CreateObservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateObservationRequest.ProtoReflect.Descriptor instead.
+func (*CreateObservationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateObservationRequest) GetObservation() *Observation {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+func (x *CreateObservationRequest) GetUnits() Units {
+	if x != nil {
+		return x.Units
+	}
+	return Units_UNITS_UNSPECIFIED
+}
+
+type GetObservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Units         Units                  `protobuf:"varint,2,opt,name=units,proto3,enum=metagrid.weather.v1.Units" json:"units,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetObservationRequest) Reset() {
+	*x = GetObservationRequest{}
+	mi := &file_weather_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetObservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetObservationRequest) ProtoMessage() {}
+
+func (x *GetObservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetObservationRequest.ProtoReflect.Descriptor instead.
+func (*GetObservationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetObservationRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GetObservationRequest) GetUnits() Units {
+	if x != nil {
+		return x.Units
+	}
+	return Units_UNITS_UNSPECIFIED
+}
+
+type UpdateObservationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The observation to correct, by id, with its new temperature and
+	// description. Other fields are ignored.
+	Observation *Observation `protobuf:"bytes,1,opt,name=observation,proto3" json:"observation,omitempty"`
+	// The unit system the corrected observation is returned in.
+	Units         Units `protobuf:"varint,2,opt,name=units,proto3,enum=metagrid.weather.v1.Units" json:"units,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateObservationRequest) Reset() {
+	*x = UpdateObservationRequest{}
+	mi := &file_weather_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateObservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateObservationRequest) ProtoMessage() {}
+
+func (x *UpdateObservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateObservationRequest.ProtoReflect.Descriptor instead.
+func (*UpdateObservationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateObservationRequest) GetObservation() *Observation {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+func (x *UpdateObservationRequest) GetUnits() Units {
+	if x != nil {
+		return x.Units
+	}
+	return Units_UNITS_UNSPECIFIED
+}
+
+type DeleteObservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteObservationRequest) Reset() {
+	*x = DeleteObservationRequest{}
+	mi := &file_weather_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteObservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteObservationRequest) ProtoMessage() {}
+
+func (x *DeleteObservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteObservationRequest.ProtoReflect.Descriptor instead.
+func (*DeleteObservationRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteObservationRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListObservationsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	StationId string                 `protobuf:"bytes,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	// from and to narrow the observations to a time range, from the beginning
+	// of time until now by default.
+	From *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// limit caps the number of observations, newest first; 100 by default.
+	Limit         int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Units         Units `protobuf:"varint,5,opt,name=units,proto3,enum=metagrid.weather.v1.Units" json:"units,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListObservationsRequest) Reset() {
+	*x = ListObservationsRequest{}
+	mi := &file_weather_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListObservationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListObservationsRequest) ProtoMessage() {}
+
+func (x *ListObservationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListObservationsRequest.ProtoReflect.Descriptor instead.
+func (*ListObservationsRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListObservationsRequest) GetStationId() string {
+	if x != nil {
+		return x.StationId
+	}
+	return ""
+}
+
+func (x *ListObservationsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListObservationsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListObservationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListObservationsRequest) GetUnits() Units {
+	if x != nil {
+		return x.Units
+	}
+	return Units_UNITS_UNSPECIFIED
+}
+
+type ListObservationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Observations  []*Observation         `protobuf:"bytes,1,rep,name=observations,proto3" json:"observations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListObservationsResponse) Reset() {
+	*x = ListObservationsResponse{}
+	mi := &file_weather_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListObservationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListObservationsResponse) ProtoMessage() {}
+
+func (x *ListObservationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListObservationsResponse.ProtoReflect.Descriptor instead.
+func (*ListObservationsResponse) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListObservationsResponse) GetObservations() []*Observation {
+	if x != nil {
+		return x.Observations
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When set, the events published after this one are sent first.
+	SinceEventId  *int64 `protobuf:"varint,1,opt,name=since_event_id,json=sinceEventId,proto3,oneof" json:"since_event_id,omitempty"`
+	Units         Units  `protobuf:"varint,2,opt,name=units,proto3,enum=metagrid.weather.v1.Units" json:"units,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_weather_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WatchRequest) GetSinceEventId() int64 {
+	if x != nil && x.SinceEventId != nil {
+		return *x.SinceEventId
+	}
+	return 0
+}
+
+func (x *WatchRequest) GetUnits() Units {
+	if x != nil {
+		return x.Units
+	}
+	return Units_UNITS_UNSPECIFIED
+}
+
+type WeatherEvent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	EventId   int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Action    Action                 `protobuf:"varint,2,opt,name=action,proto3,enum=metagrid.weather.v1.Action" json:"action,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// The station or observation after the change. When it was deleted only
+	// its id is set.
+	//
+	// Types that are valid to be assigned to Resource:
+	//
+	//	*WeatherEvent_Station
+	//	*WeatherEvent_Observation
+	Resource      isWeatherEvent_Resource `protobuf_oneof:"resource"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeatherEvent) Reset() {
+	*x = WeatherEvent{}
+	mi := &file_weather_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeatherEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeatherEvent) ProtoMessage() {}
+
+func (x *WeatherEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeatherEvent.ProtoReflect.Descriptor instead.
+func (*WeatherEvent) Descriptor() ([]byte, []int) {
+	return file_weather_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WeatherEvent) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *WeatherEvent) GetAction() Action {
+	if x != nil {
+		return x.Action
+	}
+	return Action_ACTION_UNSPECIFIED
+}
+
+func (x *WeatherEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *WeatherEvent) GetResource() isWeatherEvent_Resource {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+func (x *WeatherEvent) GetStation() *Station {
+	if x != nil {
+		if x, ok := x.Resource.(*WeatherEvent_Station); ok {
+			return x.Station
+		}
+	}
+	return nil
+}
+
+func (x *WeatherEvent) GetObservation() *Observation {
+	if x != nil {
+		if x, ok := x.Resource.(*WeatherEvent_Observation); ok {
+			return x.Observation
+		}
+	}
+	return nil
+}
+
+type isWeatherEvent_Resource interface {
+	isWeatherEvent_Resource()
+}
+
+type WeatherEvent_Station struct {
+	Station *Station `protobuf:"bytes,4,opt,name=station,proto3,oneof"`
+}
+
+type WeatherEvent_Observation struct {
+	Observation *Observation `protobuf:"bytes,5,opt,name=observation,proto3,oneof"`
+}
+
+func (*WeatherEvent_Station) isWeatherEvent_Resource() {}
+
+func (*WeatherEvent_Observation) isWeatherEvent_Resource() {}
+
+var File_weather_proto protoreflect.FileDescriptor
+
+const file_weather_proto_rawDesc = "" +
+	"\n" +
+	"\rweather.proto\x12\x13metagrid.weather.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc7\x01\n" +
+	"\aStation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\blatitude\x18\x03 \x01(\x01H\x00R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\x04 \x01(\x01H\x01R\tlongitude\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitude\"o\n" +
+	"\vMeasurement\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04unit\x18\x02 \x01(\tR\x04unit\x126\n" +
+	"\aquality\x18\x03 \x01(\x0e2\x1c.metagrid.weather.v1.QualityR\aquality\"\xef\x04\n" +
+	"\vObservation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1d\n" +
+	"\n" +
+	"station_id\x18\x02 \x01(\tR\tstationId\x12;\n" +
+	"\vobserved_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"observedAt\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12B\n" +
+	"\vtemperature\x18\x05 \x01(\v2 .metagrid.weather.v1.MeasurementR\vtemperature\x12<\n" +
+	"\bhumidity\x18\x06 \x01(\v2 .metagrid.weather.v1.MeasurementR\bhumidity\x12<\n" +
+	"\bpressure\x18\a \x01(\v2 .metagrid.weather.v1.MeasurementR\bpressure\x12?\n" +
+	"\n" +
+	"wind_speed\x18\b \x01(\v2 .metagrid.weather.v1.MeasurementR\twindSpeed\x12G\n" +
+	"\x0ewind_direction\x18\t \x01(\v2 .metagrid.weather.v1.MeasurementR\rwindDirection\x12F\n" +
+	"\rprecipitation\x18\n" +
+	" \x01(\v2 .metagrid.weather.v1.MeasurementR\rprecipitation\x12@\n" +
+	"\n" +
+	"visibility\x18\v \x01(\v2 .metagrid.weather.v1.MeasurementR\n" +
+	"visibility\"N\n" +
+	"\x14CreateStationRequest\x126\n" +
+	"\astation\x18\x01 \x01(\v2\x1c.metagrid.weather.v1.StationR\astation\"#\n" +
+	"\x11GetStationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"N\n" +
+	"\x14UpdateStationRequest\x126\n" +
+	"\astation\x18\x01 \x01(\v2\x1c.metagrid.weather.v1.StationR\astation\"&\n" +
+	"\x14DeleteStationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x15\n" +
+	"\x13ListStationsRequest\"P\n" +
+	"\x14ListStationsResponse\x128\n" +
+	"\bstations\x18\x01 \x03(\v2\x1c.metagrid.weather.v1.StationR\bstations\"\x90\x01\n" +
+	"\x18CreateObservationRequest\x12B\n" +
+	"\vobservation\x18\x01 \x01(\v2 .metagrid.weather.v1.ObservationR\vobservation\x120\n" +
+	"\x05units\x18\x02 \x01(\x0e2\x1a.metagrid.weather.v1.UnitsR\x05units\"Y\n" +
+	"\x15GetObservationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x120\n" +
+	"\x05units\x18\x02 \x01(\x0e2\x1a.metagrid.weather.v1.UnitsR\x05units\"\x90\x01\n" +
+	"\x18UpdateObservationRequest\x12B\n" +
+	"\vobservation\x18\x01 \x01(\v2 .metagrid.weather.v1.ObservationR\vobservation\x120\n" +
+	"\x05units\x18\x02 \x01(\x0e2\x1a.metagrid.weather.v1.UnitsR\x05units\"*\n" +
+	"\x18DeleteObservationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\xdc\x01\n" +
+	"\x17ListObservationsRequest\x12\x1d\n" +
+	"\n" +
+	"station_id\x18\x01 \x01(\tR\tstationId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x120\n" +
+	"\x05units\x18\x05 \x01(\x0e2\x1a.metagrid.weather.v1.UnitsR\x05units\"`\n" +
+	"\x18ListObservationsResponse\x12D\n" +
+	"\fobservations\x18\x01 \x03(\v2 .metagrid.weather.v1.ObservationR\fobservations\"~\n" +
+	"\fWatchRequest\x12)\n" +
+	"\x0esince_event_id\x18\x01 \x01(\x03H\x00R\fsinceEventId\x88\x01\x01\x120\n" +
+	"\x05units\x18\x02 \x01(\x0e2\x1a.metagrid.weather.v1.UnitsR\x05unitsB\x11\n" +
+	"\x0f_since_event_id\"\xa5\x02\n" +
+	"\fWeatherEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x123\n" +
+	"\x06action\x18\x02 \x01(\x0e2\x1b.metagrid.weather.v1.ActionR\x06action\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x128\n" +
+	"\astation\x18\x04 \x01(\v2\x1c.metagrid.weather.v1.StationH\x00R\astation\x12D\n" +
+	"\vobservation\x18\x05 \x01(\v2 .metagrid.weather.v1.ObservationH\x00R\vobservationB\n" +
+	"\n" +
+	"\bresource*R\n" +
+	"\x05Units\x12\x15\n" +
+	"\x11UNITS_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fUNITS_METRIC\x10\x01\x12\x12\n" +
+	"\x0eUNITS_IMPERIAL\x10\x02\x12\f\n" +
+	"\bUNITS_SI\x10\x03*q\n" +
+	"\aQuality\x12\x17\n" +
+	"\x13QUALITY_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fQUALITY_GOOD\x10\x01\x12\x15\n" +
+	"\x11QUALITY_ESTIMATED\x10\x02\x12\x13\n" +
+	"\x0fQUALITY_SUSPECT\x10\x03\x12\x0f\n" +
+	"\vQUALITY_BAD\x10\x04*\\\n" +
+	"\x06Action\x12\x16\n" +
+	"\x12ACTION_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eACTION_CREATED\x10\x01\x12\x12\n" +
+	"\x0eACTION_UPDATED\x10\x02\x12\x12\n" +
+	"\x0eACTION_DELETED\x10\x032\x94\b\n" +
+	"\aWeather\x12X\n" +
+	"\rCreateStation\x12).metagrid.weather.v1.CreateStationRequest\x1a\x1c.metagrid.weather.v1.Station\x12R\n" +
+	"\n" +
+	"GetStation\x12&.metagrid.weather.v1.GetStationRequest\x1a\x1c.metagrid.weather.v1.Station\x12X\n" +
+	"\rUpdateStation\x12).metagrid.weather.v1.UpdateStationRequest\x1a\x1c.metagrid.weather.v1.Station\x12R\n" +
+	"\rDeleteStation\x12).metagrid.weather.v1.DeleteStationRequest\x1a\x16.google.protobuf.Empty\x12c\n" +
+	"\fListStations\x12(.metagrid.weather.v1.ListStationsRequest\x1a).metagrid.weather.v1.ListStationsResponse\x12d\n" +
+	"\x11CreateObservation\x12-.metagrid.weather.v1.CreateObservationRequest\x1a .metagrid.weather.v1.Observation\x12^\n" +
+	"\x0eGetObservation\x12*.metagrid.weather.v1.GetObservationRequest\x1a .metagrid.weather.v1.Observation\x12d\n" +
+	"\x11UpdateObservation\x12-.metagrid.weather.v1.UpdateObservationRequest\x1a .metagrid.weather.v1.Observation\x12Z\n" +
+	"\x11DeleteObservation\x12-.metagrid.weather.v1.DeleteObservationRequest\x1a\x16.google.protobuf.Empty\x12o\n" +
+	"\x10ListObservations\x12,.metagrid.weather.v1.ListObservationsRequest\x1a-.metagrid.weather.v1.ListObservationsResponse\x12O\n" +
+	"\x05Watch\x12!.metagrid.weather.v1.WatchRequest\x1a!.metagrid.weather.v1.WeatherEvent0\x01B\x1cZ\x1ametagrid/weather/weatherpbb\x06proto3"
+
+var (
+	file_weather_proto_rawDescOnce sync.Once
+	file_weather_proto_rawDescData []byte
+)
+
+func file_weather_proto_rawDescGZIP() []byte {
+	file_weather_proto_rawDescOnce.Do(func() {
+		file_weather_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_weather_proto_rawDesc), len(file_weather_proto_rawDesc)))
+	})
+	return file_weather_proto_rawDescData
+}
+
+var file_weather_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_weather_proto_goTypes = []any{
+	(Units)(0),                       // 0: metagrid.weather.v1.Units
+	(Quality)(0),                     // 1: metagrid.weather.v1.Quality
+	(Action)(0),                      // 2: metagrid.weather.v1.Action
+	(*Station)(nil),                  // 3: metagrid.weather.v1.Station
+	(*Measurement)(nil),              // 4: metagrid.weather.v1.Measurement
+	(*Observation)(nil),              // 5: metagrid.weather.v1.Observation
+	(*CreateStationRequest)(nil),     // 6: metagrid.weather.v1.CreateStationRequest
+	(*GetStationRequest)(nil),        // 7: metagrid.weather.v1.GetStationRequest
+	(*UpdateStationRequest)(nil),     // 8: metagrid.weather.v1.UpdateStationRequest
+	(*DeleteStationRequest)(nil),     // 9: metagrid.weather.v1.DeleteStationRequest
+	(*ListStationsRequest)(nil),      // 10: metagrid.weather.v1.ListStationsRequest
+	(*ListStationsResponse)(nil),     // 11: metagrid.weather.v1.ListStationsResponse
+	(*CreateObservationRequest)(nil), // 12: metagrid.weather.v1.CreateObservationRequest
+	(*GetObservationRequest)(nil),    // 13: metagrid.weather.v1.GetObservationRequest
+	(*UpdateObservationRequest)(nil), // 14: metagrid.weather.v1.UpdateObservationRequest
+	(*DeleteObservationRequest)(nil), // 15: metagrid.weather.v1.DeleteObservationRequest
+	(*ListObservationsRequest)(nil),  // 16: metagrid.weather.v1.ListObservationsRequest
+	(*ListObservationsResponse)(nil), // 17: metagrid.weather.v1.ListObservationsResponse
+	(*WatchRequest)(nil),             // 18: metagrid.weather.v1.WatchRequest
+	(*WeatherEvent)(nil),             // 19: metagrid.weather.v1.WeatherEvent
+	(*timestamppb.Timestamp)(nil),    // 20: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),            // 21: google.protobuf.Empty
+}
+var file_weather_proto_depIdxs = []int32{
+	20, // 0: metagrid.weather.v1.Station.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 1: metagrid.weather.v1.Measurement.quality:type_name -> metagrid.weather.v1.Quality
+	20, // 2: metagrid.weather.v1.Observation.observed_at:type_name -> google.protobuf.Timestamp
+	4,  // 3: metagrid.weather.v1.Observation.temperature:type_name -> metagrid.weather.v1.Measurement
+	4,  // 4: metagrid.weather.v1.Observation.humidity:type_name -> metagrid.weather.v1.Measurement
+	4,  // 5: metagrid.weather.v1.Observation.pressure:type_name -> metagrid.weather.v1.Measurement
+	4,  // 6: metagrid.weather.v1.Observation.wind_speed:type_name -> metagrid.weather.v1.Measurement
+	4,  // 7: metagrid.weather.v1.Observation.wind_direction:type_name -> metagrid.weather.v1.Measurement
+	4,  // 8: metagrid.weather.v1.Observation.precipitation:type_name -> metagrid.weather.v1.Measurement
+	4,  // 9: metagrid.weather.v1.Observation.visibility:type_name -> metagrid.weather.v1.Measurement
+	3,  // 10: metagrid.weather.v1.CreateStationRequest.station:type_name -> metagrid.weather.v1.Station
+	3,  // 11: metagrid.weather.v1.UpdateStationRequest.station:type_name -> metagrid.weather.v1.Station
+	3,  // 12: metagrid.weather.v1.ListStationsResponse.stations:type_name -> metagrid.weather.v1.Station
+	5,  // 13: metagrid.weather.v1.CreateObservationRequest.observation:type_name -> metagrid.weather.v1.Observation
+	0,  // 14: metagrid.weather.v1.CreateObservationRequest.units:type_name -> metagrid.weather.v1.Units
+	0,  // 15: metagrid.weather.v1.GetObservationRequest.units:type_name -> metagrid.weather.v1.Units
+	5,  // 16: metagrid.weather.v1.UpdateObservationRequest.observation:type_name -> metagrid.weather.v1.Observation
+	0,  // 17: metagrid.weather.v1.UpdateObservationRequest.units:type_name -> metagrid.weather.v1.Units
+	20, // 18: metagrid.weather.v1.ListObservationsRequest.from:type_name -> google.protobuf.Timestamp
+	20, // 19: metagrid.weather.v1.ListObservationsRequest.to:type_name -> google.protobuf.Timestamp
+	0,  // 20: metagrid.weather.v1.ListObservationsRequest.units:type_name -> metagrid.weather.v1.Units
+	5,  // 21: metagrid.weather.v1.ListObservationsResponse.observations:type_name -> metagrid.weather.v1.Observation
+	0,  // 22: metagrid.weather.v1.WatchRequest.units:type_name -> metagrid.weather.v1.Units
+	2,  // 23: metagrid.weather.v1.WeatherEvent.action:type_name -> metagrid.weather.v1.Action
+	20, // 24: metagrid.weather.v1.WeatherEvent.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 25: metagrid.weather.v1.WeatherEvent.station:type_name -> metagrid.weather.v1.Station
+	5,  // 26: metagrid.weather.v1.WeatherEvent.observation:type_name -> metagrid.weather.v1.Observation
+	6,  // 27: metagrid.weather.v1.Weather.CreateStation:input_type -> metagrid.weather.v1.CreateStationRequest
+	7,  // 28: metagrid.weather.v1.Weather.GetStation:input_type -> metagrid.weather.v1.GetStationRequest
+	8,  // 29: metagrid.weather.v1.Weather.UpdateStation:input_type -> metagrid.weather.v1.UpdateStationRequest
+	9,  // 30: metagrid.weather.v1.Weather.DeleteStation:input_type -> metagrid.weather.v1.DeleteStationRequest
+	10, // 31: metagrid.weather.v1.Weather.ListStations:input_type -> metagrid.weather.v1.ListStationsRequest
+	12, // 32: metagrid.weather.v1.Weather.CreateObservation:input_type -> metagrid.weather.v1.CreateObservationRequest
+	13, // 33: metagrid.weather.v1.Weather.GetObservation:input_type -> metagrid.weather.v1.GetObservationRequest
+	14, // 34: metagrid.weather.v1.Weather.UpdateObservation:input_type -> metagrid.weather.v1.UpdateObservationRequest
+	15, // 35: metagrid.weather.v1.Weather.DeleteObservation:input_type -> metagrid.weather.v1.DeleteObservationRequest
+	16, // 36: metagrid.weather.v1.Weather.ListObservations:input_type -> metagrid.weather.v1.ListObservationsRequest
+	18, // 37: metagrid.weather.v1.Weather.Watch:input_type -> metagrid.weather.v1.WatchRequest
+	3,  // 38: metagrid.weather.v1.Weather.CreateStation:output_type -> metagrid.weather.v1.Station
+	3,  // 39: metagrid.weather.v1.Weather.GetStation:output_type -> metagrid.weather.v1.Station
+	3,  // 40: metagrid.weather.v1.Weather.UpdateStation:output_type -> metagrid.weather.v1.Station
+	21, // 41: metagrid.weather.v1.Weather.DeleteStation:output_type -> google.protobuf.Empty
+	11, // 42: metagrid.weather.v1.Weather.ListStations:output_type -> metagrid.weather.v1.ListStationsResponse
+	5,  // 43: metagrid.weather.v1.Weather.CreateObservation:output_type -> metagrid.weather.v1.Observation
+	5,  // 44: metagrid.weather.v1.Weather.GetObservation:output_type -> metagrid.weather.v1.Observation
+	5,  // 45: metagrid.weather.v1.Weather.UpdateObservation:output_type -> metagrid.weather.v1.Observation
+	21, // 46: metagrid.weather.v1.Weather.DeleteObservation:output_type -> google.protobuf.Empty
+	17, // 47: metagrid.weather.v1.Weather.ListObservations:output_type -> metagrid.weather.v1.ListObservationsResponse
+	19, // 48: metagrid.weather.v1.Weather.Watch:output_type -> metagrid.weather.v1.WeatherEvent
+	38, // [38:49] is the sub-list for method output_type
+	27, // [27:38] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
+}
+
+func init() { file_weather_proto_init() }
+func file_weather_proto_init() {
+	if File_weather_proto != nil {
+		return
+	}
+	file_weather_proto_msgTypes[0].OneofWrappers = []any{}
+	file_weather_proto_msgTypes[15].OneofWrappers = []any{}
+	file_weather_proto_msgTypes[16].OneofWrappers = []any{
+		(*WeatherEvent_Station)(nil),
+		(*WeatherEvent_Observation)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_weather_proto_rawDesc), len(file_weather_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weather_proto_goTypes,
+		DependencyIndexes: file_weather_proto_depIdxs,
+		EnumInfos:         file_weather_proto_enumTypes,
+		MessageInfos:      file_weather_proto_msgTypes,
+	}.Build()
+	File_weather_proto = out.File
+	file_weather_proto_goTypes = nil
+	file_weather_proto_depIdxs = nil
+}
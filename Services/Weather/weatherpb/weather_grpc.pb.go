@@ -0,0 +1,538 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: weather.proto
+
+package weatherpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Weather_CreateStation_FullMethodName     = "/metagrid.weather.v1.Weather/CreateStation"
+	Weather_GetStation_FullMethodName        = "/metagrid.weather.v1.Weather/GetStation"
+	Weather_UpdateStation_FullMethodName     = "/metagrid.weather.v1.Weather/UpdateStation"
+	Weather_DeleteStation_FullMethodName     = "/metagrid.weather.v1.Weather/DeleteStation"
+	Weather_ListStations_FullMethodName      = "/metagrid.weather.v1.Weather/ListStations"
+	Weather_CreateObservation_FullMethodName = "/metagrid.weather.v1.Weather/CreateObservation"
+	Weather_GetObservation_FullMethodName    = "/metagrid.weather.v1.Weather/GetObservation"
+	Weather_UpdateObservation_FullMethodName = "/metagrid.weather.v1.Weather/UpdateObservation"
+	Weather_DeleteObservation_FullMethodName = "/metagrid.weather.v1.Weather/DeleteObservation"
+	Weather_ListObservations_FullMethodName  = "/metagrid.weather.v1.Weather/ListObservations"
+	Weather_Watch_FullMethodName             = "/metagrid.weather.v1.Weather/Watch"
+)
+
+// WeatherClient is the client API for Weather service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Weather is the gRPC API of the Weather service for its stations and their
+// observations. It serves the same data as the REST API, with the same
+// validation, and streams changes from the same event log as /events.
+type WeatherClient interface {
+	CreateStation(ctx context.Context, in *CreateStationRequest, opts ...grpc.CallOption) (*Station, error)
+	GetStation(ctx context.Context, in *GetStationRequest, opts ...grpc.CallOption) (*Station, error)
+	UpdateStation(ctx context.Context, in *UpdateStationRequest, opts ...grpc.CallOption) (*Station, error)
+	// DeleteStation deletes the station's observations with it. It succeeds
+	// for a station that does not exist, as DELETE does.
+	DeleteStation(ctx context.Context, in *DeleteStationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListStations(ctx context.Context, in *ListStationsRequest, opts ...grpc.CallOption) (*ListStationsResponse, error)
+	// CreateObservation screens the observation for anomalies and evaluates
+	// the alert rules against it, like every live write.
+	CreateObservation(ctx context.Context, in *CreateObservationRequest, opts ...grpc.CallOption) (*Observation, error)
+	GetObservation(ctx context.Context, in *GetObservationRequest, opts ...grpc.CallOption) (*Observation, error)
+	// UpdateObservation corrects an observation's temperature and description,
+	// as PUT /weather/{id} does. The corrected temperature is trusted, and any
+	// pending review of the reading it replaces is closed.
+	UpdateObservation(ctx context.Context, in *UpdateObservationRequest, opts ...grpc.CallOption) (*Observation, error)
+	// DeleteObservation succeeds for an observation that does not exist.
+	DeleteObservation(ctx context.Context, in *DeleteObservationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListObservations(ctx context.Context, in *ListObservationsRequest, opts ...grpc.CallOption) (*ListObservationsResponse, error)
+	// Watch streams every change to a station or an observation until the
+	// client cancels. It ends with UNAVAILABLE when the instance shuts down or
+	// the client falls too far behind; the client then resumes from the last
+	// event it received with since_event_id.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WeatherEvent], error)
+}
+
+type weatherClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWeatherClient(cc grpc.ClientConnInterface) WeatherClient {
+	return &weatherClient{cc}
+}
+
+func (c *weatherClient) CreateStation(ctx context.Context, in *CreateStationRequest, opts ...grpc.CallOption) (*Station, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Station)
+	err := c.cc.Invoke(ctx, Weather_CreateStation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) GetStation(ctx context.Context, in *GetStationRequest, opts ...grpc.CallOption) (*Station, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Station)
+	err := c.cc.Invoke(ctx, Weather_GetStation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) UpdateStation(ctx context.Context, in *UpdateStationRequest, opts ...grpc.CallOption) (*Station, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Station)
+	err := c.cc.Invoke(ctx, Weather_UpdateStation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) DeleteStation(ctx context.Context, in *DeleteStationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Weather_DeleteStation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) ListStations(ctx context.Context, in *ListStationsRequest, opts ...grpc.CallOption) (*ListStationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStationsResponse)
+	err := c.cc.Invoke(ctx, Weather_ListStations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) CreateObservation(ctx context.Context, in *CreateObservationRequest, opts ...grpc.CallOption) (*Observation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Observation)
+	err := c.cc.Invoke(ctx, Weather_CreateObservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) GetObservation(ctx context.Context, in *GetObservationRequest, opts ...grpc.CallOption) (*Observation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Observation)
+	err := c.cc.Invoke(ctx, Weather_GetObservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) UpdateObservation(ctx context.Context, in *UpdateObservationRequest, opts ...grpc.CallOption) (*Observation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Observation)
+	err := c.cc.Invoke(ctx, Weather_UpdateObservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) DeleteObservation(ctx context.Context, in *DeleteObservationRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Weather_DeleteObservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) ListObservations(ctx context.Context, in *ListObservationsRequest, opts ...grpc.CallOption) (*ListObservationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListObservationsResponse)
+	err := c.cc.Invoke(ctx, Weather_ListObservations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WeatherEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Weather_ServiceDesc.Streams[0], Weather_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WeatherEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Weather_WatchClient = grpc.ServerStreamingClient[WeatherEvent]
+
+// WeatherServer is the server API for Weather service.
+// All implementations must embed UnimplementedWeatherServer
+// for forward compatibility.
+//
+// Weather is the gRPC API of the Weather service for its stations and their
+// observations. It serves the same data as the REST API, with the same
+// validation, and streams changes from the same event log as /events.
+type WeatherServer interface {
+	CreateStation(context.Context, *CreateStationRequest) (*Station, error)
+	GetStation(context.Context, *GetStationRequest) (*Station, error)
+	UpdateStation(context.Context, *UpdateStationRequest) (*Station, error)
+	// DeleteStation deletes the station's observations with it. It succeeds
+	// for a station that does not exist, as DELETE does.
+	DeleteStation(context.Context, *DeleteStationRequest) (*emptypb.Empty, error)
+	ListStations(context.Context, *ListStationsRequest) (*ListStationsResponse, error)
+	// CreateObservation screens the observation for anomalies and evaluates
+	// the alert rules against it, like every live write.
+	CreateObservation(context.Context, *CreateObservationRequest) (*Observation, error)
+	GetObservation(context.Context, *GetObservationRequest) (*Observation, error)
+	// UpdateObservation corrects an observation's temperature and description,
+	// as PUT /weather/{id} does. The corrected temperature is trusted, and any
+	// pending review of the reading it replaces is closed.
+	UpdateObservation(context.Context, *UpdateObservationRequest) (*Observation, error)
+	// DeleteObservation succeeds for an observation that does not exist.
+	DeleteObservation(context.Context, *DeleteObservationRequest) (*emptypb.Empty, error)
+	ListObservations(context.Context, *ListObservationsRequest) (*ListObservationsResponse, error)
+	// Watch streams every change to a station or an observation until the
+	// client cancels. It ends with UNAVAILABLE when the instance shuts down or
+	// the client falls too far behind; the client then resumes from the last
+	// event it received with since_event_id.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WeatherEvent]) error
+	mustEmbedUnimplementedWeatherServer()
+}
+
+// UnimplementedWeatherServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWeatherServer struct{}
+
+func (UnimplementedWeatherServer) CreateStation(context.Context, *CreateStationRequest) (*Station, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateStation not implemented")
+}
+func (UnimplementedWeatherServer) GetStation(context.Context, *GetStationRequest) (*Station, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStation not implemented")
+}
+func (UnimplementedWeatherServer) UpdateStation(context.Context, *UpdateStationRequest) (*Station, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateStation not implemented")
+}
+func (UnimplementedWeatherServer) DeleteStation(context.Context, *DeleteStationRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteStation not implemented")
+}
+func (UnimplementedWeatherServer) ListStations(context.Context, *ListStationsRequest) (*ListStationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStations not implemented")
+}
+func (UnimplementedWeatherServer) CreateObservation(context.Context, *CreateObservationRequest) (*Observation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateObservation not implemented")
+}
+func (UnimplementedWeatherServer) GetObservation(context.Context, *GetObservationRequest) (*Observation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetObservation not implemented")
+}
+func (UnimplementedWeatherServer) UpdateObservation(context.Context, *UpdateObservationRequest) (*Observation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateObservation not implemented")
+}
+func (UnimplementedWeatherServer) DeleteObservation(context.Context, *DeleteObservationRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteObservation not implemented")
+}
+func (UnimplementedWeatherServer) ListObservations(context.Context, *ListObservationsRequest) (*ListObservationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListObservations not implemented")
+}
+func (UnimplementedWeatherServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WeatherEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedWeatherServer) mustEmbedUnimplementedWeatherServer() {}
+func (UnimplementedWeatherServer) testEmbeddedByValue()                 {}
+
+// UnsafeWeatherServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WeatherServer will
+// result in compilation errors.
+type UnsafeWeatherServer interface {
+	mustEmbedUnimplementedWeatherServer()
+}
+
+func RegisterWeatherServer(s grpc.ServiceRegistrar, srv WeatherServer) {
+	// If the following call pancis, it indicates UnimplementedWeatherServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Weather_ServiceDesc, srv)
+}
+
+func _Weather_CreateStation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).CreateStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_CreateStation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).CreateStation(ctx, req.(*CreateStationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_GetStation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).GetStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_GetStation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).GetStation(ctx, req.(*GetStationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_UpdateStation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).UpdateStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_UpdateStation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).UpdateStation(ctx, req.(*UpdateStationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_DeleteStation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).DeleteStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_DeleteStation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).DeleteStation(ctx, req.(*DeleteStationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_ListStations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).ListStations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_ListStations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).ListStations(ctx, req.(*ListStationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_CreateObservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateObservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).CreateObservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_CreateObservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).CreateObservation(ctx, req.(*CreateObservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_GetObservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetObservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).GetObservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_GetObservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).GetObservation(ctx, req.(*GetObservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_UpdateObservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateObservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).UpdateObservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_UpdateObservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).UpdateObservation(ctx, req.(*UpdateObservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_DeleteObservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteObservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).DeleteObservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_DeleteObservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).DeleteObservation(ctx, req.(*DeleteObservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_ListObservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListObservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServer).ListObservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Weather_ListObservations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServer).ListObservations(ctx, req.(*ListObservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Weather_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WeatherServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WeatherEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Weather_WatchServer = grpc.ServerStreamingServer[WeatherEvent]
+
+// Weather_ServiceDesc is the grpc.ServiceDesc for Weather service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Weather_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "metagrid.weather.v1.Weather",
+	HandlerType: (*WeatherServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateStation",
+			Handler:    _Weather_CreateStation_Handler,
+		},
+		{
+			MethodName: "GetStation",
+			Handler:    _Weather_GetStation_Handler,
+		},
+		{
+			MethodName: "UpdateStation",
+			Handler:    _Weather_UpdateStation_Handler,
+		},
+		{
+			MethodName: "DeleteStation",
+			Handler:    _Weather_DeleteStation_Handler,
+		},
+		{
+			MethodName: "ListStations",
+			Handler:    _Weather_ListStations_Handler,
+		},
+		{
+			MethodName: "CreateObservation",
+			Handler:    _Weather_CreateObservation_Handler,
+		},
+		{
+			MethodName: "GetObservation",
+			Handler:    _Weather_GetObservation_Handler,
+		},
+		{
+			MethodName: "UpdateObservation",
+			Handler:    _Weather_UpdateObservation_Handler,
+		},
+		{
+			MethodName: "DeleteObservation",
+			Handler:    _Weather_DeleteObservation_Handler,
+		},
+		{
+			MethodName: "ListObservations",
+			Handler:    _Weather_ListObservations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Weather_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "weather.proto",
+}
@@ -0,0 +1,559 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+// Delivery states. A delivering delivery has been claimed by a dispatcher
+// until its lease expires. Dead deliveries are the dead letters: they
+// exhausted their attempts and wait for an operator to retry them.
+const (
+	deliveryPending    = "pending"
+	deliveryDelivering = "delivering"
+	deliveryDelivered  = "delivered"
+	deliveryDead       = "dead"
+)
+
+const (
+	webhookMaxAttempts  = 10
+	webhookBaseDelay    = 5 * time.Second
+	webhookMaxDelay     = time.Hour
+	webhookPollInterval = 2 * time.Second
+	webhookBatch        = 20
+	webhookTimeout      = 10 * time.Second
+	// A claimed batch is delivered one receiver after another, so its lease
+	// covers every receiver taking the full timeout.
+	webhookLease = webhookBatch*webhookTimeout + time.Minute
+)
+
+var (
+	webhookResources = map[string]bool{"observation": true, "station": true}
+	webhookActions   = map[string]bool{"created": true, "updated": true, "deleted": true}
+)
+
+// The deliveries table is the outbox: publishEvent writes one delivery per
+// matching subscription in the transaction making the change. Each delivery
+// carries a copy of its event, so pruning old events never loses a delivery
+// still waiting for its receiver or for an operator to retry it.
+var webhookTables = []string{
+	`CREATE TABLE IF NOT EXISTS weather_webhooks (
+		id SERIAL PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		resources TEXT[] NOT NULL DEFAULT '{}',
+		actions TEXT[] NOT NULL DEFAULT '{}',
+		resource_ids TEXT[] NOT NULL DEFAULT '{}',
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS weather_webhook_deliveries (
+		id BIGSERIAL PRIMARY KEY,
+		webhook_id INTEGER NOT NULL REFERENCES weather_webhooks (id) ON DELETE CASCADE,
+		event_id BIGINT NOT NULL,
+		event_resource TEXT NOT NULL,
+		event_action TEXT NOT NULL,
+		event_resource_id TEXT NOT NULL,
+		event_data JSONB,
+		event_created_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_status INTEGER,
+		last_error TEXT NOT NULL DEFAULT '',
+		delivered_at TIMESTAMP
+	)`,
+	`ALTER TABLE weather_webhook_deliveries ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP`,
+	// Deliveries queued before they carried their event copy it from the
+	// events table, which then no longer deletes them.
+	`ALTER TABLE weather_webhook_deliveries
+		ADD COLUMN IF NOT EXISTS event_resource TEXT,
+		ADD COLUMN IF NOT EXISTS event_action TEXT,
+		ADD COLUMN IF NOT EXISTS event_resource_id TEXT,
+		ADD COLUMN IF NOT EXISTS event_data JSONB,
+		ADD COLUMN IF NOT EXISTS event_created_at TIMESTAMP`,
+	`UPDATE weather_webhook_deliveries d SET event_resource = e.resource, event_action = e.action,
+		event_resource_id = e.resource_id, event_data = e.data, event_created_at = e.created_at
+	FROM weather_events e
+	WHERE e.id = d.event_id AND d.event_resource IS NULL`,
+	`ALTER TABLE weather_webhook_deliveries DROP CONSTRAINT IF EXISTS weather_webhook_deliveries_event_id_fkey`,
+	`CREATE INDEX IF NOT EXISTS weather_webhook_deliveries_due_idx
+		ON weather_webhook_deliveries (next_attempt_at) WHERE status = 'pending'`,
+	`CREATE INDEX IF NOT EXISTS weather_webhook_deliveries_lease_idx
+		ON weather_webhook_deliveries (lease_expires_at) WHERE status = 'delivering'`,
+}
+
+// Webhook is a subscription to events. An empty filter list matches
+// everything; the secret is only returned when the webhook is created.
+type Webhook struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	Resources   []string  `json:"resources"`
+	Actions     []string  `json:"actions"`
+	ResourceIDs []string  `json:"resource_ids"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is one event queued for one webhook.
+type WebhookDelivery struct {
+	ID            int64      `json:"id"`
+	WebhookID     int        `json:"webhook_id"`
+	EventID       int64      `json:"event_id"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastStatus    *int       `json:"last_status,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// enqueueWebhooks queues e for every enabled webhook whose filters match it.
+func enqueueWebhooks(tx *sql.Tx, e Event) error {
+	_, err := tx.Exec(
+		`INSERT INTO weather_webhook_deliveries
+			(webhook_id, event_id, event_resource, event_action, event_resource_id, event_data, event_created_at)
+		SELECT id, $1, $2, $3, $4, $5::jsonb, $6::timestamp FROM weather_webhooks
+		WHERE enabled
+			AND (cardinality(resources) = 0 OR $2 = ANY(resources))
+			AND (cardinality(actions) = 0 OR $3 = ANY(actions))
+			AND (cardinality(resource_ids) = 0 OR $4 = ANY(resource_ids))`,
+		e.ID, e.Resource, e.Action, e.ResourceID, nullableJSON(e.Data), e.CreatedAt,
+	)
+	return err
+}
+
+// runWebhookDispatcher delivers due webhooks. Every replica runs it; claiming
+// a batch with a lease keeps a delivery to one of them at a time.
+func runWebhookDispatcher() {
+	client := &http.Client{Timeout: webhookTimeout}
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := requeueExpiredDeliveries(); err != nil {
+			slog.Error("Failed to requeue expired webhook deliveries", "error", err)
+		}
+		for {
+			n, err := dispatchWebhooks(client)
+			if err != nil {
+				slog.Error("Webhook dispatch failed", "error", err)
+			}
+			if err != nil || n < webhookBatch {
+				break
+			}
+		}
+	}
+}
+
+type pendingDelivery struct {
+	id       int64
+	attempts int
+	url      string
+	secret   string
+	event    Event
+}
+
+// dispatchWebhooks claims one batch of due deliveries, delivers them and
+// returns its size. The claim commits before any receiver is called, and
+// each outcome is recorded on its own, so no locks or connections are held
+// while receivers answer.
+func dispatchWebhooks(client *http.Client) (int, error) {
+	batch, err := claimDeliveries()
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range batch {
+		status, err := deliverWebhook(client, d)
+		if err := recordDelivery(d, status, err); err != nil {
+			slog.Error("Failed to record webhook delivery", "delivery_id", d.id, "error", err)
+		}
+	}
+	return len(batch), nil
+}
+
+// claimDeliveries marks a batch of due deliveries as delivering until
+// webhookLease from now and returns them.
+func claimDeliveries() ([]pendingDelivery, error) {
+	rows, err := db.Query(
+		`WITH claimed AS (
+			UPDATE weather_webhook_deliveries SET status = $1, lease_expires_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT d.id FROM weather_webhook_deliveries d
+				JOIN weather_webhooks w ON w.id = d.webhook_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND w.enabled
+				ORDER BY d.next_attempt_at, d.id
+				LIMIT $3
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING id, attempts, webhook_id, event_id, event_resource, event_action, event_resource_id, event_data,
+				event_created_at
+		)
+		SELECT c.id, c.attempts, w.url, w.secret, c.event_id, c.event_resource, c.event_action, c.event_resource_id,
+			c.event_data, c.event_created_at
+		FROM claimed c
+		JOIN weather_webhooks w ON w.id = c.webhook_id
+		ORDER BY c.id`,
+		deliveryDelivering, webhookLease.Seconds(), webhookBatch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []pendingDelivery
+	for rows.Next() {
+		var d pendingDelivery
+		var data []byte
+		if err := rows.Scan(&d.id, &d.attempts, &d.url, &d.secret, &d.event.ID, &d.event.Resource,
+			&d.event.Action, &d.event.ResourceID, &data, &d.event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if data != nil {
+			d.event.Data = data
+		}
+		batch = append(batch, d)
+	}
+	return batch, rows.Err()
+}
+
+// recordDelivery records the outcome of an attempt and releases the lease.
+// A delivery whose lease expired and was requeued meanwhile is left alone.
+func recordDelivery(d pendingDelivery, status int, deliveryErr error) error {
+	if deliveryErr == nil {
+		_, err := db.Exec(
+			`UPDATE weather_webhook_deliveries SET status = $1, attempts = attempts + 1, last_status = $2,
+				last_error = '', delivered_at = CURRENT_TIMESTAMP, lease_expires_at = NULL
+			WHERE id = $3 AND status = $4`,
+			deliveryDelivered, status, d.id, deliveryDelivering,
+		)
+		return err
+	}
+
+	var lastStatus *int
+	if status != 0 {
+		lastStatus = &status
+	}
+	state := deliveryPending
+	if d.attempts+1 >= webhookMaxAttempts {
+		state = deliveryDead
+	}
+	_, err := db.Exec(
+		`UPDATE weather_webhook_deliveries SET status = $1, attempts = attempts + 1, last_status = $2, last_error = $3,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $4), lease_expires_at = NULL
+		WHERE id = $5 AND status = $6`,
+		state, lastStatus, deliveryErr.Error(), webhookBackoff(d.attempts).Seconds(), d.id, deliveryDelivering,
+	)
+	return err
+}
+
+// requeueExpiredDeliveries puts back deliveries whose dispatcher stopped
+// before recording them, such as a replica that was killed. The lost attempt
+// counts, so a delivery that keeps taking its dispatcher down ends up dead.
+func requeueExpiredDeliveries() error {
+	_, err := db.Exec(
+		`UPDATE weather_webhook_deliveries
+		SET status = CASE WHEN attempts + 1 >= $1 THEN $2 ELSE $3 END,
+			attempts = attempts + 1, last_error = 'delivery lease expired',
+			next_attempt_at = CURRENT_TIMESTAMP, lease_expires_at = NULL
+		WHERE status = $4 AND lease_expires_at < CURRENT_TIMESTAMP`,
+		webhookMaxAttempts, deliveryDead, deliveryPending, deliveryDelivering,
+	)
+	return err
+}
+
+// webhookBackoff doubles the delay after every failed attempt up to
+// webhookMaxDelay, with up to 20% jitter so failing receivers are not hit in
+// lockstep.
+func webhookBackoff(attempts int) time.Duration {
+	delay := time.Duration(float64(webhookBaseDelay) * math.Pow(2, float64(attempts)))
+	if delay > webhookMaxDelay || delay <= 0 {
+		delay = webhookMaxDelay
+	}
+	return delay + time.Duration(mathrand.Float64()*0.2*float64(delay))
+}
+
+// deliverWebhook posts the event signed with the webhook's secret. Receivers
+// verify X-MetaGrid-Signature, the hex HMAC-SHA256 of the timestamp header,
+// a dot and the body. Any 2xx response counts as delivered.
+func deliverWebhook(client *http.Client, d pendingDelivery) (int, error) {
+	body, err := json.Marshal(d.event)
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(d.secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MetaGrid-Webhooks/1.0")
+	req.Header.Set("X-MetaGrid-Event", d.event.Resource+"."+d.event.Action)
+	req.Header.Set("X-MetaGrid-Delivery", strconv.FormatInt(d.id, 10))
+	req.Header.Set("X-MetaGrid-Timestamp", timestamp)
+	req.Header.Set("X-MetaGrid-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func decodeWebhook(r *http.Request) (Webhook, error) {
+	webhook := Webhook{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		return webhook, errors.New("Invalid input")
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return webhook, errors.New("url must be an absolute http or https URL")
+	}
+	for _, resource := range webhook.Resources {
+		if !webhookResources[resource] {
+			return webhook, fmt.Errorf("Unknown resource %q", resource)
+		}
+	}
+	for _, action := range webhook.Actions {
+		if !webhookActions[action] {
+			return webhook, fmt.Errorf("Unknown action %q, expected created, updated or deleted", action)
+		}
+	}
+	webhook.Resources = nonNil(webhook.Resources)
+	webhook.Actions = nonNil(webhook.Actions)
+	webhook.ResourceIDs = nonNil(webhook.ResourceIDs)
+	return webhook, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+const webhookColumns = `id, url, resources, actions, resource_ids, enabled, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }) (Webhook, error) {
+	var webhook Webhook
+	err := row.Scan(&webhook.ID, &webhook.URL, pq.Array(&webhook.Resources), pq.Array(&webhook.Actions),
+		pq.Array(&webhook.ResourceIDs), &webhook.Enabled, &webhook.CreatedAt)
+	webhook.Resources = nonNil(webhook.Resources)
+	webhook.Actions = nonNil(webhook.Actions)
+	webhook.ResourceIDs = nonNil(webhook.ResourceIDs)
+	return webhook, err
+}
+
+// addWebhook registers a subscription. Without a secret one is generated;
+// either way it is returned once in the response.
+func addWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, err := decodeWebhook(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			http.Error(w, "Failed to add webhook", http.StatusInternalServerError)
+			return
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	err = db.QueryRowContext(r.Context(),
+		`INSERT INTO weather_webhooks (url, secret, resources, actions, resource_ids, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		webhook.URL, webhook.Secret, pq.Array(webhook.Resources), pq.Array(webhook.Actions),
+		pq.Array(webhook.ResourceIDs), webhook.Enabled,
+	).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		http.Error(w, "Failed to add webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+func listWebhooks(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.QueryContext(r.Context(), `SELECT `+webhookColumns+` FROM weather_webhooks ORDER BY id`)
+	if err != nil {
+		http.Error(w, "Failed to query webhooks", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			http.Error(w, "Failed to read webhooks", http.StatusInternalServerError)
+			return
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+func getWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	webhook, err := scanWebhook(db.QueryRowContext(r.Context(), `SELECT `+webhookColumns+` FROM weather_webhooks WHERE id = $1`, id))
+	if err != nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// updateWebhook replaces a subscription's URL and filters. The secret is kept
+// unless a new one is given.
+func updateWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	webhook, err := decodeWebhook(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.ExecContext(r.Context(),
+		`UPDATE weather_webhooks SET url = $1, secret = COALESCE(NULLIF($2, ''), secret), resources = $3, actions = $4,
+			resource_ids = $5, enabled = $6
+		WHERE id = $7`,
+		webhook.URL, webhook.Secret, pq.Array(webhook.Resources), pq.Array(webhook.Actions),
+		pq.Array(webhook.ResourceIDs), webhook.Enabled, id,
+	)
+	if err != nil {
+		http.Error(w, "Failed to update webhook", http.StatusInternalServerError)
+		return
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Webhook %s updated", id)
+}
+
+func deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	_, err := db.ExecContext(r.Context(), `DELETE FROM weather_webhooks WHERE id = $1`, id)
+	if err != nil {
+		http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("Webhook deleted"))
+}
+
+// listWebhookDeliveries lists a webhook's most recent deliveries, optionally
+// only those in one state; status=dead lists its dead letters.
+func listWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", deliveryPending, deliveryDelivering, deliveryDelivered, deliveryDead:
+	default:
+		http.Error(w, fmt.Sprintf("Unknown status %q", status), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.QueryContext(r.Context(),
+		`SELECT id, webhook_id, event_id, status, attempts, next_attempt_at, last_status, last_error, delivered_at
+		FROM weather_webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC LIMIT 500`,
+		id, status,
+	)
+	if err != nil {
+		http.Error(w, "Failed to query webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatus, &d.LastError, &d.DeliveredAt); err != nil {
+			http.Error(w, "Failed to read webhook deliveries", http.StatusInternalServerError)
+			return
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// retryWebhookDelivery puts a dead or delivered delivery back in the queue
+// with a fresh set of attempts.
+func retryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	deliveryID := chi.URLParam(r, "deliveryID")
+
+	// A pending or delivering delivery is still in the queue; retrying it
+	// would let a second dispatcher send it alongside the first.
+	result, err := db.ExecContext(r.Context(),
+		`UPDATE weather_webhook_deliveries SET status = $1, attempts = 0, next_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND webhook_id = $3 AND status IN ($4, $5)`,
+		deliveryPending, deliveryID, id, deliveryDead, deliveryDelivered,
+	)
+	if err != nil {
+		http.Error(w, "Failed to retry webhook delivery", http.StatusInternalServerError)
+		return
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		var exists bool
+		if err := db.QueryRowContext(r.Context(),
+			`SELECT EXISTS (SELECT 1 FROM weather_webhook_deliveries WHERE id = $1 AND webhook_id = $2)`,
+			deliveryID, id,
+		).Scan(&exists); err != nil {
+			http.Error(w, "Failed to retry webhook delivery", http.StatusInternalServerError)
+			return
+		}
+		if exists {
+			http.Error(w, "Webhook delivery is still queued", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Webhook delivery not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Webhook delivery %s queued for retry", deliveryID)
+}
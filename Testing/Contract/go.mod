@@ -0,0 +1,7 @@
+module testing/contract
+
+go 1.23.3
+
+require metagrid/client v1.0.0
+
+replace metagrid/client => ../../Client
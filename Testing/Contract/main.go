@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"metagrid/client"
+)
+
+// Service is a service whose OpenAPI document is checked
+type Service struct {
+	Name string // as named to client.WithBaseURL
+	Dir  string // directory under Services holding openapi.json
+	URL  string
+	// Paths are requested as they are, to validate operations the client
+	// does not cover
+	Paths []string
+}
+
+// ClientType pairs a type of the Go client with the schema it must match
+type ClientType struct {
+	Service string
+	Schema  string
+	Type    any
+	Request bool // sent by the client rather than received
+}
+
+var clientTypes = []ClientType{
+	{client.ServiceTraffic, "TrafficLight", client.TrafficLight{}, false},
+	{client.ServiceTraffic, "TrafficLightInput", client.TrafficLightInput{}, true},
+	{client.ServiceParking, "ParkingSpot", client.ParkingSpot{}, false},
+	{client.ServiceParking, "ParkingSpotInput", client.ParkingSpotInput{}, true},
+	{client.ServiceWeather, "WeatherEntry", client.WeatherEntry{}, false},
+	{client.ServiceWeather, "WeatherEntryInput", client.WeatherEntryInput{}, true},
+	{client.ServiceWeather, "WeatherEntryUpdate", client.WeatherEntryUpdate{}, true},
+	{client.ServiceWeather, "Alert", client.WeatherAlert{}, false},
+	{client.ServiceWeather, "WeatherForecast", client.WeatherForecast{}, false},
+}
+
+// Validator is a transport that checks every response against the documents
+type Validator struct {
+	specs map[string]*Spec // by host
+	next  http.RoundTripper
+
+	mu       sync.Mutex
+	checked  int
+	problems []string
+}
+
+func (v *Validator) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := v.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := v.specs[req.URL.Host]
+	if !ok {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	problems := spec.checkResponse(req, resp.StatusCode, resp.Header, body)
+	v.mu.Lock()
+	v.checked++
+	v.problems = append(v.problems, problems...)
+	v.mu.Unlock()
+	return resp, nil
+}
+
+func main() {
+	servicesDir := flag.String("services", "../../Services", "directory holding the service sources")
+	static := flag.Bool("static", false, "only check the documents and the client, without running services")
+	trafficURL := flag.String("traffic", "http://traffic.localhost", "base URL of the Traffic service")
+	parkingURL := flag.String("parking", "http://parking.localhost", "base URL of the Parking service")
+	weatherURL := flag.String("weather", "http://weather.localhost", "base URL of the Weather service")
+	flag.Parse()
+
+	common := []string{"/webhooks", "/livez", "/readyz", "/startupz", "/health", "/metrics", "/admin/log-level", "/docs"}
+	services := []Service{
+		{client.ServiceTraffic, "Traffic", *trafficURL, common},
+		{client.ServiceParking, "Parking", *parkingURL, common},
+		{client.ServiceWeather, "Weather", *weatherURL, append([]string{
+			"/stations", "/alert-rules", "/anomalies", "/retention/status", "/retention/policies",
+			"/weather/aggregate?location=contract-test",
+		}, common...)},
+	}
+
+	specs := make(map[string]*Spec)
+	failed := false
+	report := func(what string, problems []string) {
+		if len(problems) == 0 {
+			fmt.Printf("%s: ok\n", what)
+			return
+		}
+		failed = true
+		fmt.Printf("%s: %d problems\n", what, len(problems))
+		for _, problem := range problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+
+	for _, service := range services {
+		spec, err := loadSpec(filepath.Join(*servicesDir, service.Dir, "openapi.json"))
+		if err != nil {
+			fmt.Printf("Error loading the %s document: %v\n", service.Name, err)
+			os.Exit(1)
+		}
+		specs[service.Name] = spec
+		report(service.Name+" document", spec.checkDocument())
+	}
+
+	for _, service := range services {
+		var problems []string
+		for _, ct := range clientTypes {
+			if ct.Service != service.Name {
+				continue
+			}
+			schema, ok := specs[ct.Service].Components.Schemas[ct.Schema]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("schema %s is not in the document", ct.Schema))
+				continue
+			}
+			t := reflect.TypeOf(ct.Type)
+			problems = append(problems, specs[ct.Service].checkClientType("client."+t.Name(), t, schema, ct.Request)...)
+		}
+		report(service.Name+" client types", problems)
+	}
+
+	if !*static {
+		for _, problems := range checkLive(services, specs) {
+			report(problems.what, problems.problems)
+		}
+	}
+
+	if failed {
+		fmt.Println("FAIL: the services, their OpenAPI documents and the client disagree")
+		os.Exit(1)
+	}
+	fmt.Println("PASS: the services, their OpenAPI documents and the client agree")
+}
+
+type result struct {
+	what     string
+	problems []string
+}
+
+// checkLive compares the documents the services serve with the files, then
+// exercises each service through the client and by plain requests,
+// validating every response
+func checkLive(services []Service, specs map[string]*Spec) []result {
+	var results []result
+	byHost := make(map[string]*Spec)
+	opts := []client.Option{client.WithUserAgent("contract-test")}
+	for _, service := range services {
+		req, err := http.NewRequest(http.MethodGet, service.URL, nil)
+		if err != nil {
+			results = append(results, result{service.Name + " URL", []string{err.Error()}})
+			return results
+		}
+		byHost[req.URL.Host] = specs[service.Name]
+		opts = append(opts, client.WithBaseURL(service.Name, service.URL))
+	}
+	validator := &Validator{specs: byHost, next: http.DefaultTransport}
+	opts = append(opts, client.WithTransport(validator))
+	c, err := client.New(opts...)
+	if err != nil {
+		return append(results, result{"client", []string{err.Error()}})
+	}
+
+	plain := &http.Client{Timeout: 30 * time.Second}
+	for _, service := range services {
+		results = append(results, result{service.Name + " served document", checkServedDocument(plain, service, specs[service.Name])})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	validated := &http.Client{Timeout: 30 * time.Second, Transport: validator}
+	flows := map[string]func(context.Context, *client.Client) []string{
+		client.ServiceTraffic: trafficFlow,
+		client.ServiceParking: parkingFlow,
+		client.ServiceWeather: weatherFlow,
+	}
+	for _, service := range services {
+		validator.mu.Lock()
+		before := validator.checked
+		validator.mu.Unlock()
+
+		problems := flows[service.Name](ctx, c)
+		for _, path := range service.Paths {
+			resp, err := validated.Get(service.URL + path)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("GET %s: %v", path, err))
+				continue
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		validator.mu.Lock()
+		problems = append(problems, validator.problems...)
+		validator.problems = nil
+		checked := validator.checked - before
+		validator.mu.Unlock()
+		sort.Strings(problems)
+		results = append(results, result{fmt.Sprintf("%s responses (%d checked)", service.Name, checked), problems})
+	}
+	return results
+}
+
+// checkServedDocument makes sure a service runs with the document in its
+// source tree
+func checkServedDocument(hc *http.Client, service Service, spec *Spec) []string {
+	resp, err := hc.Get(service.URL + "/openapi.json")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return []string{fmt.Sprintf("GET /openapi.json: status %d", resp.StatusCode)}
+	}
+	var served, file any
+	if err := json.NewDecoder(resp.Body).Decode(&served); err != nil {
+		return []string{fmt.Sprintf("GET /openapi.json: %v", err)}
+	}
+	json.Unmarshal(spec.raw, &file)
+	if !reflect.DeepEqual(served, file) {
+		return []string{"the served document differs from openapi.json; the running image is out of date"}
+	}
+	return nil
+}
+
+// expectNotFound reports a lookup of a deleted resource that did not fail
+// with 404
+func expectNotFound(what string, err error) []string {
+	if errors.Is(err, client.ErrNotFound) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s after deleting it: want 404, got %v", what, err)}
+}
+
+func trafficFlow(ctx context.Context, c *client.Client) []string {
+	id, err := c.Traffic.Create(ctx, client.TrafficLightInput{Location: "Contract Test Ave", Color: "red"})
+	if err != nil {
+		return []string{fmt.Sprintf("create traffic light: %v", err)}
+	}
+	var problems []string
+	check := func(what string, err error) {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", what, err))
+		}
+	}
+	_, err = c.Traffic.Get(ctx, id)
+	check("get traffic light", err)
+	check("set color", c.Traffic.SetColor(ctx, id, "green"))
+	_, err = c.Traffic.List(ctx, nil)
+	check("list traffic lights", err)
+	_, err = c.Traffic.List(ctx, &client.ListOptions{Limit: 1})
+	check("list a page of traffic lights", err)
+	check("delete traffic light", c.Traffic.Delete(ctx, id))
+	_, err = c.Traffic.Get(ctx, id)
+	problems = append(problems, expectNotFound("get traffic light", err)...)
+	return problems
+}
+
+func parkingFlow(ctx context.Context, c *client.Client) []string {
+	id, err := c.Parking.Create(ctx, client.ParkingSpotInput{Location: "Contract Test Lot", Availability: true})
+	if err != nil {
+		return []string{fmt.Sprintf("create parking spot: %v", err)}
+	}
+	var problems []string
+	check := func(what string, err error) {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", what, err))
+		}
+	}
+	_, err = c.Parking.Get(ctx, id)
+	check("get parking spot", err)
+	check("set availability", c.Parking.SetAvailability(ctx, id, false))
+	_, err = c.Parking.List(ctx, nil)
+	check("list parking spots", err)
+	_, err = c.Parking.List(ctx, &client.ListOptions{Limit: 1})
+	check("list a page of parking spots", err)
+	check("delete parking spot", c.Parking.Delete(ctx, id))
+	_, err = c.Parking.Get(ctx, id)
+	problems = append(problems, expectNotFound("get parking spot", err)...)
+	return problems
+}
+
+func weatherFlow(ctx context.Context, c *client.Client) []string {
+	id, err := c.Weather.CreateEntry(ctx, client.WeatherEntryInput{Location: "contract-test", Temperature: 21.5, Description: "Contract test"}, client.Metric)
+	if err != nil {
+		return []string{fmt.Sprintf("create weather entry: %v", err)}
+	}
+	var problems []string
+	check := func(what string, err error) {
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", what, err))
+		}
+	}
+	_, err = c.Weather.GetEntry(ctx, id, client.Imperial)
+	check("get weather entry", err)
+	check("update weather entry", c.Weather.UpdateEntry(ctx, id, client.WeatherEntryUpdate{Temperature: 22, Description: "Contract test"}, client.Metric))
+	_, err = c.Weather.ListEntries(ctx, client.SI, &client.ListOptions{Limit: 1})
+	check("list a page of weather entries", err)
+	_, err = c.Weather.ListAlerts(ctx, client.Metric)
+	check("list alerts", err)
+	// A new station has too little history to forecast; the 422 is checked
+	// against the document like any other response.
+	if _, err := c.Weather.Forecast(ctx, "contract-test", nil); err != nil && !errors.Is(err, client.ErrUnprocessable) {
+		check("forecast", err)
+	}
+	check("delete weather entry", c.Weather.DeleteEntry(ctx, id))
+	_, err = c.Weather.GetEntry(ctx, id, client.Metric)
+	problems = append(problems, expectNotFound("get weather entry", err)...)
+	return problems
+}
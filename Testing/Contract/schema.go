@@ -0,0 +1,440 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Spec is the part of a service's OpenAPI document the checks read
+type Spec struct {
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components struct {
+		Schemas   map[string]*Schema   `json:"schemas"`
+		Responses map[string]*Response `json:"responses"`
+	} `json:"components"`
+
+	raw json.RawMessage
+}
+
+// Operation is an operation's documented responses, by status code
+type Operation struct {
+	Responses map[string]*Response `json:"responses"`
+}
+
+// Response is a documented response, or a reference to one
+type Response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of an OpenAPI schema object the documents use
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []any              `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *Schema            `json:"items"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+}
+
+var operationMethods = []string{"get", "put", "post", "delete", "patch", "head", "options", "trace"}
+
+func loadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// Path items may hold parameters next to the operations, so they are
+	// decoded one operation at a time.
+	var paths map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(doc["paths"], &paths); err != nil {
+		return nil, fmt.Errorf("%s: paths: %w", path, err)
+	}
+	spec := &Spec{Paths: make(map[string]map[string]Operation), raw: data}
+	if err := json.Unmarshal(doc["components"], &spec.Components); err != nil {
+		return nil, fmt.Errorf("%s: components: %w", path, err)
+	}
+	for p, item := range paths {
+		spec.Paths[p] = make(map[string]Operation)
+		for method, raw := range item {
+			if !slices.Contains(operationMethods, method) {
+				continue
+			}
+			var op Operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("%s: %s %s: %w", path, method, p, err)
+			}
+			spec.Paths[p][method] = op
+		}
+	}
+	return spec, nil
+}
+
+// checkDocument reports references that lead nowhere and operations without
+// responses
+func (s *Spec) checkDocument() []string {
+	var problems []string
+	for p, item := range s.Paths {
+		for method, op := range item {
+			if len(op.Responses) == 0 {
+				problems = append(problems, fmt.Sprintf("%s %s has no responses", strings.ToUpper(method), p))
+			}
+			for status, resp := range op.Responses {
+				r := s.response(resp)
+				if r == nil {
+					problems = append(problems, fmt.Sprintf("%s %s: response %s refers to %s, which does not exist", strings.ToUpper(method), p, status, resp.Ref))
+					continue
+				}
+				for mediaType, content := range r.Content {
+					if content.Schema != nil {
+						problems = append(problems, s.checkRefs(fmt.Sprintf("%s %s %s %s", strings.ToUpper(method), p, status, mediaType), content.Schema)...)
+					}
+				}
+			}
+		}
+	}
+	for name, schema := range s.Components.Schemas {
+		problems = append(problems, s.checkRefs(name, schema)...)
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func (s *Spec) checkRefs(at string, schema *Schema) []string {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		if s.resolve(schema) == nil {
+			return []string{fmt.Sprintf("%s: unknown schema %s", at, schema.Ref)}
+		}
+		return nil
+	}
+	var problems []string
+	for name, property := range schema.Properties {
+		problems = append(problems, s.checkRefs(at+"."+name, property)...)
+	}
+	for _, sub := range slices.Concat(schema.AllOf, schema.OneOf, []*Schema{schema.Items, schema.AdditionalProperties}) {
+		problems = append(problems, s.checkRefs(at, sub)...)
+	}
+	return problems
+}
+
+func (s *Spec) response(r *Response) *Response {
+	if r.Ref == "" {
+		return r
+	}
+	return s.Components.Responses[strings.TrimPrefix(r.Ref, "#/components/responses/")]
+}
+
+// resolve follows a reference, and a single allOf used to make one nullable,
+// to the schema it names. It returns nil for an unknown reference.
+func (s *Spec) resolve(schema *Schema) *Schema {
+	if len(schema.AllOf) == 1 {
+		schema = schema.AllOf[0]
+	}
+	if schema.Ref == "" {
+		return schema
+	}
+	return s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+}
+
+// operation finds the operation serving method and path, preferring the
+// template with the most literal segments, so /weather/aggregate is not
+// taken for /weather/{id}
+func (s *Spec) operation(method, path string) (string, *Operation) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	best, bestLiterals := "", -1
+	for template, item := range s.Paths {
+		if _, ok := item[strings.ToLower(method)]; !ok {
+			continue
+		}
+		parts := strings.Split(strings.Trim(template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		literals := 0
+		for i, part := range parts {
+			if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+				continue
+			}
+			if part != segments[i] {
+				literals = -1
+				break
+			}
+			literals++
+		}
+		if literals > bestLiterals {
+			best, bestLiterals = template, literals
+		}
+	}
+	if best == "" {
+		return "", nil
+	}
+	op := s.Paths[best][strings.ToLower(method)]
+	return best, &op
+}
+
+// checkResponse validates a response against the operation serving its
+// request: the status must be documented, with the media type sent, and a
+// JSON body must match the schema exactly
+func (s *Spec) checkResponse(req *http.Request, status int, header http.Header, body []byte) []string {
+	template, op := s.operation(req.Method, req.URL.Path)
+	at := req.Method + " " + req.URL.Path
+	if op == nil {
+		return []string{fmt.Sprintf("%s is not documented", at)}
+	}
+	at = req.Method + " " + template
+	resp, ok := op.Responses[fmt.Sprint(status)]
+	if !ok {
+		return []string{fmt.Sprintf("%s: status %d is not documented", at, status)}
+	}
+	resp = s.response(resp)
+	if resp == nil || len(resp.Content) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return []string{fmt.Sprintf("%s %d: invalid Content-Type %q", at, status, header.Get("Content-Type"))}
+	}
+	content, ok := resp.Content[mediaType]
+	if !ok {
+		documented := make([]string, 0, len(resp.Content))
+		for t := range resp.Content {
+			documented = append(documented, t)
+		}
+		sort.Strings(documented)
+		return []string{fmt.Sprintf("%s %d: sent as %s, documented as %s", at, status, mediaType, strings.Join(documented, " or "))}
+	}
+	if mediaType != "application/json" || content.Schema == nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{fmt.Sprintf("%s %d: invalid JSON: %v", at, status, err)}
+	}
+	return s.validate(fmt.Sprintf("%s %d body", at, status), value, content.Schema)
+}
+
+// validate checks a decoded JSON value against schema. Objects may not have
+// properties the schema does not declare.
+func (s *Spec) validate(at string, value any, schema *Schema) []string {
+	nullable := schema.Nullable
+	if schema = s.resolve(schema); schema == nil {
+		return []string{fmt.Sprintf("%s: unknown schema", at)}
+	}
+	if value == nil {
+		if nullable || schema.Nullable {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null is not allowed", at)}
+	}
+	if len(schema.OneOf) > 0 {
+		var all []string
+		for _, option := range schema.OneOf {
+			problems := s.validate(at, value, option)
+			if len(problems) == 0 {
+				return nil
+			}
+			all = append(all, problems...)
+		}
+		return append([]string{fmt.Sprintf("%s: matches none of the alternatives", at)}, all...)
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return reflect.DeepEqual(e, value) }) {
+		return []string{fmt.Sprintf("%s: %v is not one of %v", at, value, schema.Enum)}
+	}
+
+	wrongType := func() []string {
+		return []string{fmt.Sprintf("%s: %s is not of type %s", at, describe(value), schema.Type)}
+	}
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return wrongType()
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return []string{fmt.Sprintf("%s: %q is not a date-time", at, str)}
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return wrongType()
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return wrongType()
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return wrongType()
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return wrongType()
+		}
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, s.validate(fmt.Sprintf("%s[%d]", at, i), item, schema.Items)...)
+		}
+		return problems
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return wrongType()
+		}
+		var problems []string
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: required property %s is missing", at, name))
+			}
+		}
+		for name, v := range object {
+			property, ok := schema.Properties[name]
+			switch {
+			case ok:
+				problems = append(problems, s.validate(at+"."+name, v, property)...)
+			case schema.AdditionalProperties != nil:
+				problems = append(problems, s.validate(at+"."+name, v, schema.AdditionalProperties)...)
+			case schema.Properties != nil:
+				problems = append(problems, fmt.Sprintf("%s: property %s is not documented", at, name))
+			}
+		}
+		sort.Strings(problems)
+		return problems
+	default:
+		return []string{fmt.Sprintf("%s: unknown type %s", at, schema.Type)}
+	}
+	return nil
+}
+
+func describe(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// checkClientType compares a struct of the Go client with schema. A response
+// type may leave properties out, as it decodes only what it needs, but every
+// field must be a documented property of a matching type. A request type
+// must also have every required property.
+func (s *Spec) checkClientType(at string, t reflect.Type, schema *Schema, request bool) []string {
+	if schema = s.resolve(schema); schema == nil {
+		return []string{fmt.Sprintf("%s: unknown schema", at)}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	mismatch := func(want string) []string {
+		return []string{fmt.Sprintf("%s: the client's %s is %s but documented as %s", at, t, want, strings.TrimSpace(schema.Type+" "+schema.Format))}
+	}
+	if t == timeType {
+		if schema.Type != "string" || schema.Format != "date-time" {
+			return mismatch("a date-time")
+		}
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if schema.Type != "string" {
+			return mismatch("a string")
+		}
+	case reflect.Bool:
+		if schema.Type != "boolean" {
+			return mismatch("a boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if schema.Type != "integer" {
+			return mismatch("an integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		if schema.Type != "number" && schema.Type != "integer" {
+			return mismatch("a number")
+		}
+	case reflect.Slice, reflect.Array:
+		if schema.Type != "array" || schema.Items == nil {
+			return mismatch("an array")
+		}
+		return s.checkClientType(at+"[]", t.Elem(), schema.Items, request)
+	case reflect.Map:
+		if schema.Type != "object" || schema.AdditionalProperties == nil {
+			return mismatch("a map")
+		}
+		return s.checkClientType(at+"{}", t.Elem(), schema.AdditionalProperties, request)
+	case reflect.Struct:
+		if schema.Type != "object" {
+			return mismatch("a struct")
+		}
+		var problems []string
+		fields := make(map[string]bool)
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if !field.IsExported() || tag == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+			fields[name] = true
+			property, ok := schema.Properties[name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: the client's field %s is not documented", at, name))
+				continue
+			}
+			problems = append(problems, s.checkClientType(at+"."+name, field.Type, property, request)...)
+		}
+		if request {
+			for _, name := range schema.Required {
+				if !fields[name] {
+					problems = append(problems, fmt.Sprintf("%s: the client cannot send required property %s", at, name))
+				}
+			}
+		}
+		return problems
+	default:
+		return []string{fmt.Sprintf("%s: the client's %s has no JSON schema", at, t)}
+	}
+	return nil
+}
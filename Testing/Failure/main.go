@@ -99,7 +99,7 @@ func main() {
 			fmt.Printf("Testing %s...\n", serviceName)
 			service := Service{
 				Name:       serviceName,
-				HealthURL:  fmt.Sprintf("http://%s.localhost/health", serviceName),
+				HealthURL:  fmt.Sprintf("http://%s.localhost/readyz", serviceName),
 				ConsulKey:  serviceName,
 				DockerName: serviceName,
 			}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"bufio"
 	"encoding/csv"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -110,6 +114,13 @@ func main() {
 		testServices(services, level, csvWriter)
 	}
 
+	// Each service is replicated behind Traefik, so these come from whichever
+	// instance answers the scrape.
+	fmt.Println("Server-side latency of /health:")
+	for _, service := range services {
+		printServerPercentiles(service)
+	}
+
 	for _, dir := range serviceDirs {
 		fmt.Printf("Stopping service in directory: %s\n", dir)
 		if err := runDockerComposeDown(dir); err != nil {
@@ -230,3 +241,69 @@ func runDockerComposeDown(dir string) error {
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
+
+// printServerPercentiles estimates p50, p95 and p99 of the service's /health
+// route from the http_request_duration_seconds histogram on its /metrics
+// endpoint, interpolating within buckets like Prometheus' histogram_quantile.
+func printServerPercentiles(s Service) {
+	metricsURL := strings.TrimSuffix(s.URL, "/health") + "/metrics"
+	resp, err := http.Get(metricsURL)
+	if err != nil {
+		fmt.Printf("Error requesting %s: %v\n", metricsURL, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	// Cumulative counts by upper bound, summed over methods and statuses.
+	buckets := map[float64]float64{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "http_request_duration_seconds_bucket{") || !strings.Contains(line, `route="/health"`) {
+			continue
+		}
+		_, le, ok := strings.Cut(line, `le="`)
+		if !ok {
+			continue
+		}
+		le, _, _ = strings.Cut(le, `"`)
+		bound, err := strconv.ParseFloat(le, 64) // ParseFloat accepts "+Inf"
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseFloat(line[strings.LastIndex(line, " ")+1:], 64)
+		if err != nil {
+			continue
+		}
+		buckets[bound] += count
+	}
+
+	total := buckets[math.Inf(1)]
+	if total == 0 {
+		fmt.Printf("%s: no /health requests recorded\n", s.Name)
+		return
+	}
+	bounds := make([]float64, 0, len(buckets))
+	for bound := range buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	quantile := func(q float64) float64 {
+		rank := q * total
+		lower, below := 0.0, 0.0
+		for _, bound := range bounds {
+			if buckets[bound] >= rank {
+				if math.IsInf(bound, 1) {
+					return lower
+				}
+				return lower + (bound-lower)*(rank-below)/(buckets[bound]-below)
+			}
+			lower, below = bound, buckets[bound]
+		}
+		return lower
+	}
+
+	fmt.Printf("%s: p50 %.2fms, p95 %.2fms, p99 %.2fms over %.0f requests\n",
+		s.Name, quantile(0.5)*1000, quantile(0.95)*1000, quantile(0.99)*1000, total)
+}
@@ -0,0 +1,3 @@
+module testing/rollingrestart
+
+go 1.23.3
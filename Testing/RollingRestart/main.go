@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Service is a service whose replicas are restarted while it is under load
+type Service struct {
+	Name     string // compose service and Consul service name
+	URL      string // endpoint requested throughout the restart
+	Replicas int
+}
+
+// LoadResult counts the requests made while the replicas were restarted
+type LoadResult struct {
+	Requests int64
+	Failures int64
+	Samples  []string // first failures, for the report
+}
+
+const maxSamples = 10
+
+func main() {
+	workers := flag.Int("workers", 8, "concurrent clients sending requests")
+	grace := flag.Duration("grace", 35*time.Second, "time docker waits for a container to stop, matching stop_grace_period")
+	only := flag.String("service", "", "restart only this service (traffic, parking or weather)")
+	flag.Parse()
+
+	services := []Service{
+		{"traffic", "http://traffic.localhost/traffic-lights", 5},
+		{"parking", "http://parking.localhost/parking", 5},
+		{"weather", "http://weather.localhost/stations", 5},
+	}
+
+	resp, err := http.Get("http://localhost:8500/v1/status/leader")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		log.Fatalf("Failed to query Consul or received non-200 status code: %v", err)
+	}
+	resp.Body.Close()
+	fmt.Println("Successfully connected to Consul")
+
+	failed := false
+	for _, service := range services {
+		if *only != "" && service.Name != *only {
+			continue
+		}
+		result, err := testRollingRestart(service, *workers, *grace)
+		if err != nil {
+			fmt.Printf("Error testing %s: %v\n", service.Name, err)
+			failed = true
+			continue
+		}
+
+		fmt.Printf("%s: %d requests, %d failed\n", service.Name, result.Requests, result.Failures)
+		for _, sample := range result.Samples {
+			fmt.Printf("  %s\n", sample)
+		}
+		if result.Failures > 0 {
+			failed = true
+		}
+	}
+
+	if failed {
+		fmt.Println("FAIL: requests failed during a rolling restart")
+		os.Exit(1)
+	}
+	fmt.Println("PASS: no failed requests during rolling restarts")
+}
+
+// testRollingRestart restarts the replicas of a service one at a time while
+// workers request it through Traefik, waiting after each restart until every
+// replica passes its Consul check again.
+func testRollingRestart(service Service, workers int, grace time.Duration) (LoadResult, error) {
+	fmt.Printf("\nRolling restart of %s...\n", service.Name)
+	if err := waitForPassing(service, 2*time.Minute); err != nil {
+		return LoadResult{}, err
+	}
+
+	containers, err := listContainers(service.Name)
+	if err != nil {
+		return LoadResult{}, err
+	}
+	if len(containers) != service.Replicas {
+		return LoadResult{}, fmt.Errorf("found %d containers, expected %d", len(containers), service.Replicas)
+	}
+
+	stop := make(chan struct{})
+	result, wait := generateLoad(service.URL, workers, stop)
+
+	var restartErr error
+	for i, container := range containers {
+		start := time.Now()
+		fmt.Printf("Restarting replica %d of %d (%s)...\n", i+1, len(containers), container)
+		cmd := exec.Command("docker", "restart", "--time", fmt.Sprintf("%d", int(grace.Seconds())), container)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			restartErr = fmt.Errorf("failed to restart %s: %v: %s", container, err, strings.TrimSpace(string(out)))
+			break
+		}
+		if err := waitForPassing(service, 2*time.Minute); err != nil {
+			restartErr = err
+			break
+		}
+		fmt.Printf("Replica %d back in rotation after %.1f seconds\n", i+1, time.Since(start).Seconds())
+	}
+
+	close(stop)
+	wait()
+	return *result, restartErr
+}
+
+// generateLoad sends requests from the given number of workers until stop is
+// closed. A request fails on a transport error or a status other than 200.
+func generateLoad(url string, workers int, stop <-chan struct{}) (*LoadResult, func()) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	result := &LoadResult{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				atomic.AddInt64(&result.Requests, 1)
+				failure := ""
+				resp, err := client.Get(url)
+				if err != nil {
+					failure = err.Error()
+				} else {
+					body, _ := io.ReadAll(resp.Body)
+					resp.Body.Close()
+					if resp.StatusCode != http.StatusOK {
+						failure = fmt.Sprintf("%d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+					}
+				}
+				if failure != "" {
+					atomic.AddInt64(&result.Failures, 1)
+					mu.Lock()
+					if len(result.Samples) < maxSamples {
+						result.Samples = append(result.Samples, time.Now().Format("15:04:05.000")+" "+failure)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	return result, wg.Wait
+}
+
+// listContainers returns the IDs of the running containers of a compose service
+func listContainers(service string) ([]string, error) {
+	out, err := exec.Command("docker", "ps", "-q", "--filter", "label=com.docker.compose.service="+service).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %v", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// waitForPassing waits until Consul has every replica of the service
+// registered and passing its readiness check
+func waitForPassing(service Service, timeout time.Duration) error {
+	url := fmt.Sprintf("http://localhost:8500/v1/health/service/%s?passing", service.Name)
+	deadline := time.Now().Add(timeout)
+	for {
+		var entries []json.RawMessage
+		resp, err := http.Get(url)
+		if err == nil {
+			err = json.NewDecoder(resp.Body).Decode(&entries)
+			resp.Body.Close()
+		}
+		if err == nil && len(entries) >= service.Replicas {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s has %d of %d replicas passing after %s", service.Name, len(entries), service.Replicas, timeout)
+		}
+		time.Sleep(time.Second)
+	}
+}
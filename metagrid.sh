@@ -20,18 +20,16 @@ function to_lowercase() {
 function add_hosts() {
     echo "Adding hosts to /etc/hosts..."
     
-    # Check if entries already exist
-    if grep -q "parking.localhost" /etc/hosts && \
-       grep -q "traffic.localhost" /etc/hosts && \
-       grep -q "weather.localhost" /etc/hosts; then
-        echo "Host entries already exist in /etc/hosts. Skipping."
-        return
-    fi
-
-    # Add entries to /etc/hosts
-    echo "127.0.0.1 parking.localhost" | sudo tee -a /etc/hosts
-    echo "127.0.0.1 traffic.localhost" | sudo tee -a /etc/hosts
-    echo "127.0.0.1 weather.localhost" | sudo tee -a /etc/hosts
+    # Add each entry that is missing, so hosts added in later versions are
+    # picked up without duplicating the ones already there
+    for host in parking.localhost traffic.localhost weather.localhost \
+                parking-grpc.localhost traffic-grpc.localhost weather-grpc.localhost; do
+        if grep -qxF "127.0.0.1 $host" /etc/hosts; then
+            echo "$host already in /etc/hosts. Skipping."
+        else
+            echo "127.0.0.1 $host" | sudo tee -a /etc/hosts
+        fi
+    done
 
     echo "Hosts added successfully."
 }
@@ -57,7 +55,7 @@ for service in Parking Traffic Weather; do
     if [ -d "Services/$service" ]; then
         change_dir "Services/$service"
         echo "Building $service Service..."
-        docker build -t "$(to_lowercase $service)-service" . || { echo "Failed to build $service service"; exit 1; }
+        docker build --build-arg VERSION="$(git describe --always --dirty 2>/dev/null || echo dev)" -t "$(to_lowercase $service)-service" . || { echo "Failed to build $service service"; exit 1; }
         cd ../..  # Return to the project root directory after each build
     else
         echo "Warning: Directory for $service service not found. Skipping."
@@ -71,7 +69,7 @@ if [ -d "Testing" ]; then
     change_dir "Testing"
 
     # Run tests
-    for test in Latency StartupTime Failure; do
+    for test in Contract Latency StartupTime Failure; do
         if [ -d "$test" ]; then
             echo "Starting Phase: $test Test..."
             change_dir "$test"
@@ -30,7 +30,7 @@ for service in Parking Traffic Weather; do
   if [ -d "Services/$service" ]; then
     change_dir "Services/$service"
     echo "Building $service Service..."
-    docker build -t "$(to_lowercase $service)-service" . || {
+    docker build --build-arg VERSION="$(git describe --always --dirty 2>/dev/null || echo dev)" -t "$(to_lowercase $service)-service" . || {
       echo "Failed to build $service service"
       exit 1
     }
@@ -49,7 +49,7 @@ echo "All services are built and running."
 if [ -d "web2" ]; then
   change_dir "web2"
   echo "Running MetaGrid web application..."
-  go run . &
+  OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318 go run . &
   WEB_PID=$!
   wait $WEB_PID
 else
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"metagrid/client"
+)
+
+// Section states in API responses.
+const (
+	sectionOK          = "ok"          // the service answered its last fetch; see fetched_at
+	sectionStale       = "stale"       // the service failed; the last snapshot is served
+	sectionUnavailable = "unavailable" // the service failed and there is no snapshot
+)
+
+// SectionData is one service's part of an API response. A failing service
+// does not fail the response: its section says so, and carries the last
+// snapshot when there is one. Sections are served from a cache, so even an
+// ok section may have been fetched up to snapshotFresh+snapshotRevalidate
+// ago; FetchedAt says when.
+type SectionData[T any] struct {
+	Status     string     `json:"status"`
+	Items      []T        `json:"items"`
+	FetchedAt  *time.Time `json:"fetched_at,omitempty"`
+	StaleSince *time.Time `json:"stale_since,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// CitySnapshot is every dashboard section at once, with the weather sections
+// in the same units.
+type CitySnapshot struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Units       string    `json:"units"`
+	// Complete is false when any section is stale or unavailable. It does
+	// not mean the sections were fetched at the same time.
+	Complete       bool                             `json:"complete"`
+	TrafficLights  SectionData[client.TrafficLight] `json:"traffic_lights"`
+	ParkingSpots   SectionData[client.ParkingSpot]  `json:"parking_spots"`
+	WeatherEntries SectionData[client.WeatherEntry] `json:"weather_entries"`
+	Alerts         SectionData[client.WeatherAlert] `json:"alerts"`
+}
+
+// loadSection returns the list cache holds for key, keeping only the items
+// keep accepts.
+func loadSection[T any](ctx context.Context, cache *snapshotCache[[]T], key string, keep func(T) bool) SectionData[T] {
+	items, fetchedAt, stale, err := cache.get(ctx, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching section", "section", cache.name, "error", err)
+		return SectionData[T]{Status: sectionUnavailable, Items: []T{}, Error: err.Error()}
+	}
+
+	data := SectionData[T]{Status: sectionOK, Items: make([]T, 0, len(items)), FetchedAt: &fetchedAt}
+	if stale {
+		data.Status, data.StaleSince = sectionStale, &fetchedAt
+	}
+	for _, item := range items {
+		if keep(item) {
+			data.Items = append(data.Items, item)
+		}
+	}
+	return data
+}
+
+// trafficFilter selects traffic lights. Zero fields match every light.
+type trafficFilter struct {
+	Color    string
+	Location string
+}
+
+func (f trafficFilter) keep(light client.TrafficLight) bool {
+	return (f.Color == "" || light.Color == f.Color) && matchLocation(light.Location, f.Location)
+}
+
+// parkingFilter selects parking spots. A nil Available matches every spot.
+type parkingFilter struct {
+	Available *bool
+	Location  string
+}
+
+func (f parkingFilter) keep(spot client.ParkingSpot) bool {
+	return (f.Available == nil || spot.Availability == *f.Available) && matchLocation(spot.Location, f.Location)
+}
+
+// weatherEntryFilter selects weather entries by location.
+type weatherEntryFilter struct {
+	Location string
+}
+
+func (f weatherEntryFilter) keep(entry client.WeatherEntry) bool {
+	return matchLocation(entry.Location, f.Location)
+}
+
+// alertFilter selects firing alerts. Zero fields match every alert.
+type alertFilter struct {
+	Severity  string
+	StationID string
+}
+
+func (f alertFilter) keep(alert client.WeatherAlert) bool {
+	return (f.Severity == "" || alert.Severity == f.Severity) && (f.StationID == "" || alert.StationID == f.StationID)
+}
+
+// matchLocation reports whether location contains query, ignoring case. An
+// empty query matches every location.
+func matchLocation(location, query string) bool {
+	return strings.Contains(strings.ToLower(location), strings.ToLower(query))
+}
+
+func (app *App) trafficLightsData(ctx context.Context, f trafficFilter) SectionData[client.TrafficLight] {
+	return loadSection(ctx, app.trafficLights, "", f.keep)
+}
+
+func (app *App) parkingSpotsData(ctx context.Context, f parkingFilter) SectionData[client.ParkingSpot] {
+	return loadSection(ctx, app.parkingSpots, "", f.keep)
+}
+
+func (app *App) weatherEntriesData(ctx context.Context, units string, f weatherEntryFilter) SectionData[client.WeatherEntry] {
+	return loadSection(ctx, app.weatherEntries, units, f.keep)
+}
+
+func (app *App) alertsData(ctx context.Context, units string, f alertFilter) SectionData[client.WeatherAlert] {
+	return loadSection(ctx, app.alerts, units, f.keep)
+}
+
+// citySnapshot fetches every section concurrently, so the snapshot takes as
+// long as the slowest service rather than all of them in turn.
+func (app *App) citySnapshot(ctx context.Context, units string) CitySnapshot {
+	snapshot := CitySnapshot{Units: units}
+	var wg sync.WaitGroup
+	for _, load := range []func(){
+		func() { snapshot.TrafficLights = app.trafficLightsData(ctx, trafficFilter{}) },
+		func() { snapshot.ParkingSpots = app.parkingSpotsData(ctx, parkingFilter{}) },
+		func() { snapshot.WeatherEntries = app.weatherEntriesData(ctx, units, weatherEntryFilter{}) },
+		func() { snapshot.Alerts = app.alertsData(ctx, units, alertFilter{}) },
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			load()
+		}()
+	}
+	wg.Wait()
+
+	snapshot.GeneratedAt = time.Now().UTC()
+	snapshot.Complete = snapshot.TrafficLights.Status == sectionOK &&
+		snapshot.ParkingSpots.Status == sectionOK &&
+		snapshot.WeatherEntries.Status == sectionOK &&
+		snapshot.Alerts.Status == sectionOK
+	return snapshot
+}
+
+// registerAPI adds the JSON API under /api/v1/. It serves the same data as
+// the dashboard fragments, for scripts and other clients.
+func (app *App) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/snapshot", app.apiSnapshotHandler)
+	mux.HandleFunc("GET /api/v1/traffic-lights", app.apiTrafficLightsHandler)
+	mux.HandleFunc("GET /api/v1/parking-spots", app.apiParkingSpotsHandler)
+	mux.HandleFunc("GET /api/v1/weather/entries", app.apiWeatherEntriesHandler)
+	mux.HandleFunc("GET /api/v1/weather/alerts", app.apiAlertsHandler)
+}
+
+// apiUnits returns the units query parameter, or the units cookie when there
+// is none.
+func apiUnits(r *http.Request) (string, error) {
+	units := r.URL.Query().Get("units")
+	if units == "" {
+		return unitsPreference(r), nil
+	}
+	if _, ok := temperatureUnits[units]; !ok {
+		return "", fmt.Errorf("units must be metric, imperial or si")
+	}
+	return units, nil
+}
+
+// City Snapshot Handler. Sections fail on their own, so the snapshot is sent
+// with 200 as long as web2 can build it.
+func (app *App) apiSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	units, err := apiUnits(r)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, app.citySnapshot(r.Context(), units))
+}
+
+func (app *App) apiTrafficLightsHandler(w http.ResponseWriter, r *http.Request) {
+	f := trafficFilter{Color: r.URL.Query().Get("color"), Location: r.URL.Query().Get("location")}
+	switch f.Color {
+	case "", "red", "yellow", "green":
+	default:
+		writeJSONError(w, "color must be red, yellow or green", http.StatusBadRequest)
+		return
+	}
+	writeSection(w, r, app.trafficLightsData(r.Context(), f))
+}
+
+func (app *App) apiParkingSpotsHandler(w http.ResponseWriter, r *http.Request) {
+	f := parkingFilter{Location: r.URL.Query().Get("location")}
+	if s := r.URL.Query().Get("available"); s != "" {
+		available, err := strconv.ParseBool(s)
+		if err != nil {
+			writeJSONError(w, "available must be true or false", http.StatusBadRequest)
+			return
+		}
+		f.Available = &available
+	}
+	writeSection(w, r, app.parkingSpotsData(r.Context(), f))
+}
+
+func (app *App) apiWeatherEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	units, err := apiUnits(r)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f := weatherEntryFilter{Location: r.URL.Query().Get("location")}
+	writeSection(w, r, app.weatherEntriesData(r.Context(), units, f))
+}
+
+func (app *App) apiAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	units, err := apiUnits(r)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f := alertFilter{Severity: r.URL.Query().Get("severity"), StationID: r.URL.Query().Get("station")}
+	writeSection(w, r, app.alertsData(r.Context(), units, f))
+}
+
+// writeSection sends a single service's section, with 503 when the service
+// is down and there is no snapshot to serve.
+func writeSection[T any](w http.ResponseWriter, r *http.Request, data SectionData[T]) {
+	status := http.StatusOK
+	if data.Status == sectionUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, r, status, data)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding response", "error", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// Balancing strategies, chosen with LB_STRATEGY.
+const (
+	roundRobin        = "round-robin"
+	leastOutstanding  = "least-outstanding"
+	powerOfTwoChoices = "p2c"
+)
+
+// endpoint is an instance of a service with the number of requests web2 has
+// in flight to it. Endpoints outlive changes to the instance list, so the
+// counts of instances that stay are kept.
+type endpoint struct {
+	Instance
+	outstanding atomic.Int64
+}
+
+// balancer picks the endpoint for the next request to a service among its
+// endpoints, of which there is at least one.
+type balancer func(s *serviceInstances, endpoints []*endpoint) *endpoint
+
+func newBalancer(strategy string) (balancer, error) {
+	switch strategy {
+	case "", roundRobin:
+		return pickRoundRobin, nil
+	case leastOutstanding:
+		return pickLeastOutstanding, nil
+	case powerOfTwoChoices:
+		return pickPowerOfTwo, nil
+	default:
+		return nil, fmt.Errorf("unknown balancing strategy %q: use %s, %s or %s", strategy, roundRobin, leastOutstanding, powerOfTwoChoices)
+	}
+}
+
+// pickRoundRobin takes the endpoints in turn.
+func pickRoundRobin(s *serviceInstances, endpoints []*endpoint) *endpoint {
+	return endpoints[(s.turn.Add(1)-1)%uint64(len(endpoints))]
+}
+
+// pickLeastOutstanding takes the endpoint with the fewest requests in flight.
+// The scan starts at a random endpoint so ties are spread out.
+func pickLeastOutstanding(_ *serviceInstances, endpoints []*endpoint) *endpoint {
+	start := rand.IntN(len(endpoints))
+	best := endpoints[start]
+	for i := 1; i < len(endpoints); i++ {
+		if e := endpoints[(start+i)%len(endpoints)]; e.outstanding.Load() < best.outstanding.Load() {
+			best = e
+		}
+	}
+	return best
+}
+
+// pickPowerOfTwo takes the less loaded of two random endpoints, which avoids
+// the herding of least-outstanding when the counts are stale or equal, at the
+// cost of two reads.
+func pickPowerOfTwo(_ *serviceInstances, endpoints []*endpoint) *endpoint {
+	if len(endpoints) == 1 {
+		return endpoints[0]
+	}
+	i := rand.IntN(len(endpoints))
+	j := rand.IntN(len(endpoints) - 1)
+	if j >= i {
+		j++
+	}
+	if endpoints[j].outstanding.Load() < endpoints[i].outstanding.Load() {
+		return endpoints[j]
+	}
+	return endpoints[i]
+}
+
+// trackedBody ends a request's outstanding count once its response body has
+// been read to the end or closed, so streams count for as long as they last.
+type trackedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(b.done)
+	return b.ReadCloser.Close()
+}
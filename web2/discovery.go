@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// discoveryTransport sends each request for http://<service>.localhost to an
+// instance of the service found in the registry, chosen by the balancer. The
+// handlers keep naming services by the hosts Traefik routes, so with the
+// default registry requests reach Traefik unchanged; with the Consul registry
+// web2 balances across the healthy instances itself and Traefik is not needed.
+type discoveryTransport struct {
+	registry Registry
+	balance  balancer
+	next     http.RoundTripper
+
+	mu       sync.Mutex
+	services map[string]*serviceInstances
+}
+
+// serviceInstances is the latest list of a service's instances, kept up to
+// date by a watch on the registry.
+type serviceInstances struct {
+	mu        sync.RWMutex
+	endpoints []*endpoint
+	err       error
+	loaded    chan struct{} // closed once the watch has started or failed
+	turn      atomic.Uint64 // next endpoint for round-robin
+}
+
+func newDiscoveryTransport(registry Registry, balance balancer, next http.RoundTripper) *discoveryTransport {
+	return &discoveryTransport{registry: registry, balance: balance, next: next, services: make(map[string]*serviceInstances)}
+}
+
+func (t *discoveryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, ok := strings.CutSuffix(req.URL.Hostname(), ".localhost")
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	s, endpoints, err := t.endpoints(req.Context(), service)
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no instances of %s available", service)
+	}
+	e := t.balance(s, endpoints)
+
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.URL.Host = e.HostPort()
+	req.Host = ""
+
+	e.outstanding.Add(1)
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		e.outstanding.Add(-1)
+		return nil, err
+	}
+	resp.Body = &trackedBody{ReadCloser: resp.Body, done: func() { e.outstanding.Add(-1) }}
+	return resp, nil
+}
+
+// endpoints returns the endpoints of service, starting to watch the service
+// the first time it is called.
+func (t *discoveryTransport) endpoints(ctx context.Context, service string) (*serviceInstances, []*endpoint, error) {
+	t.mu.Lock()
+	s, ok := t.services[service]
+	if !ok {
+		s = &serviceInstances{loaded: make(chan struct{})}
+		t.services[service] = s
+		go t.watch(service, s)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-s.loaded:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s, s.endpoints, s.err
+}
+
+// watch follows the instances of service for the life of the process. If the
+// registry refuses the watch, the service is forgotten so the next request
+// tries again.
+func (t *discoveryTransport) watch(service string, s *serviceInstances) {
+	updates, err := t.registry.Watch(context.Background(), service)
+	if err != nil {
+		slog.Error("Failed to watch service instances", "service", service, "error", err)
+		s.mu.Lock()
+		s.err = fmt.Errorf("resolving %s: %w", service, err)
+		s.mu.Unlock()
+		close(s.loaded)
+
+		t.mu.Lock()
+		delete(t.services, service)
+		t.mu.Unlock()
+		return
+	}
+
+	first := true
+	for instances := range updates {
+		s.update(instances)
+		if first {
+			close(s.loaded)
+			first = false
+		}
+		slog.Debug("Service instances changed", "service", service, "instances", len(instances))
+	}
+}
+
+// update replaces the endpoints, keeping those of instances still listed.
+func (s *serviceInstances) update(instances []Instance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := make(map[string]*endpoint, len(s.endpoints))
+	for _, e := range s.endpoints {
+		current[e.ID] = e
+	}
+	endpoints := make([]*endpoint, 0, len(instances))
+	for _, instance := range instances {
+		e, ok := current[instance.ID]
+		if !ok || e.HostPort() != instance.HostPort() {
+			e = &endpoint{Instance: instance}
+		}
+		endpoints = append(endpoints, e)
+	}
+	s.endpoints = endpoints
+}
+
+// status returns the instances of service found so far and the watch error,
+// without starting a watch.
+func (t *discoveryTransport) status(service string) ([]InstanceStatus, error) {
+	t.mu.Lock()
+	s, ok := t.services[service]
+	t.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	instances := make([]InstanceStatus, 0, len(s.endpoints))
+	for _, e := range s.endpoints {
+		instances = append(instances, InstanceStatus{ID: e.ID, Address: e.HostPort(), Outstanding: e.outstanding.Load()})
+	}
+	return instances, s.err
+}
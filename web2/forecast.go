@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"metagrid/client"
+)
+
+// forecastChart holds the SVG geometry for plotting a forecast against the
+// observed values preceding it.
+type forecastChart struct {
+	Width, Height      int
+	Observed, Forecast string // polyline points
+	Band               string // polygon points of the prediction interval
+	MinLabel, MaxLabel string
+	StartLabel         string
+	NowX               float64
+	EndLabel           string
+}
+
+const (
+	chartWidth   = 600
+	chartHeight  = 200
+	chartPadding = 30
+)
+
+func newForecastChart(f *client.WeatherForecast) forecastChart {
+	chart := forecastChart{Width: chartWidth, Height: chartHeight}
+	if len(f.Observed) == 0 || len(f.Forecast) == 0 {
+		return chart
+	}
+
+	start, end := f.Observed[0].Time, f.Forecast[len(f.Forecast)-1].Time
+	lo, hi := f.Observed[0].Value, f.Observed[0].Value
+	for _, p := range f.Observed {
+		lo, hi = min(lo, p.Value), max(hi, p.Value)
+	}
+	for _, p := range f.Forecast {
+		lo, hi = min(lo, p.Lower), max(hi, p.Upper)
+	}
+	if hi == lo {
+		hi, lo = hi+1, lo-1
+	}
+
+	x := func(t time.Time) float64 {
+		span := end.Sub(start)
+		if span <= 0 {
+			return chartPadding
+		}
+		return chartPadding + float64(t.Sub(start))/float64(span)*(chartWidth-2*chartPadding)
+	}
+	y := func(v float64) float64 {
+		return chartHeight - chartPadding - (v-lo)/(hi-lo)*(chartHeight-2*chartPadding)
+	}
+	point := func(t time.Time, v float64) string {
+		return fmt.Sprintf("%.1f,%.1f", x(t), y(v))
+	}
+
+	var observed, forecast, upper, lower []string
+	for _, p := range f.Observed {
+		observed = append(observed, point(p.Time, p.Value))
+	}
+	last := f.Observed[len(f.Observed)-1]
+	// Start the forecast line at the last observation so the two lines join.
+	forecast = append(forecast, point(last.Time, last.Value))
+	upper = append(upper, point(last.Time, last.Value))
+	lower = append(lower, point(last.Time, last.Value))
+	for _, p := range f.Forecast {
+		forecast = append(forecast, point(p.Time, p.Value))
+		upper = append(upper, point(p.Time, p.Upper))
+		lower = append([]string{point(p.Time, p.Lower)}, lower...)
+	}
+
+	chart.Observed = strings.Join(observed, " ")
+	chart.Forecast = strings.Join(forecast, " ")
+	chart.Band = strings.Join(append(upper, lower...), " ")
+	chart.MinLabel = fmt.Sprintf("%.1f%s", lo, f.Unit)
+	chart.MaxLabel = fmt.Sprintf("%.1f%s", hi, f.Unit)
+	chart.StartLabel = start.Local().Format("Jan 2 15:04")
+	chart.EndLabel = end.Local().Format("15:04")
+	chart.NowX = x(last.Time)
+	return chart
+}
+
+var forecastTemplate = template.Must(template.New("weather-forecast").Funcs(template.FuncMap{
+	"mul100": func(v float64) float64 { return v * 100 },
+}).Parse(`
+<div class="weather-forecast">
+    <strong>{{.Forecast.StationID}}</strong> {{.Forecast.Metric}} forecast ({{.Forecast.Model}}, {{.Forecast.Level}}% interval)
+    <svg width="{{.Chart.Width}}" height="{{.Chart.Height}}" viewBox="0 0 {{.Chart.Width}} {{.Chart.Height}}" style="display: block; margin: 10px 0;">
+        <polygon points="{{.Chart.Band}}" fill="#2196f3" fill-opacity="0.2" stroke="none"></polygon>
+        <line x1="{{.Chart.NowX}}" y1="20" x2="{{.Chart.NowX}}" y2="{{.Chart.Height}}" stroke="#bbb" stroke-dasharray="2,2"></line>
+        <polyline points="{{.Chart.Observed}}" fill="none" stroke="#333" stroke-width="2"></polyline>
+        <polyline points="{{.Chart.Forecast}}" fill="none" stroke="#2196f3" stroke-width="2" stroke-dasharray="6,4"></polyline>
+        <text x="2" y="34" font-size="11">{{.Chart.MaxLabel}}</text>
+        <text x="2" y="{{.Chart.Height}}" dy="-22" font-size="11">{{.Chart.MinLabel}}</text>
+        <text x="30" y="{{.Chart.Height}}" dy="-4" font-size="11">{{.Chart.StartLabel}}</text>
+        <text x="{{.Chart.Width}}" y="{{.Chart.Height}}" dx="-60" dy="-4" font-size="11">{{.Chart.EndLabel}}</text>
+    </svg>
+    <div>
+        {{range .Forecast.Forecast}}
+        <span style="margin-right: 10px;">{{.Time.Local.Format "15:04"}}: {{printf "%.1f" .Value}}{{$.Forecast.Unit}} ({{printf "%.1f" .Lower}} to {{printf "%.1f" .Upper}})</span>
+        {{end}}
+    </div>
+    {{with .Forecast.Backtest}}
+    <small>Backtest: MAE {{.MAE}}, RMSE {{.RMSE}}, interval coverage {{printf "%.0f" (mul100 .Coverage)}}%</small>
+    {{end}}
+</div>`))
+
+// Weather Forecast Handler
+func (app *App) weatherForecastHandler(w http.ResponseWriter, r *http.Request) {
+	location := r.FormValue("location")
+	if location == "" {
+		http.Error(w, "Location is required", http.StatusBadRequest)
+		return
+	}
+
+	forecast, err := app.api.Weather.Forecast(r.Context(), location, &client.ForecastOptions{Units: client.Units(unitsPreference(r))})
+	// An unknown station or one with too little history has no forecast;
+	// the service explains why.
+	var apiErr *client.APIError
+	if errors.Is(err, client.ErrNotFound) || errors.Is(err, client.ErrUnprocessable) {
+		errors.As(err, &apiErr)
+		fmt.Fprintf(w, `<div class="no-entries">%s</div>`, template.HTMLEscapeString(apiErr.Message))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error fetching weather forecast", "error", err)
+		http.Error(w, "Failed to fetch weather forecast", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Forecast *client.WeatherForecast
+		Chart    forecastChart
+	}{forecast, newForecastChart(forecast)}
+	if err := forecastTemplate.Execute(w, data); err != nil {
+		slog.ErrorContext(r.Context(), "Error executing template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import "html/template"
+
+// fragments holds the dashboard lists, their rows and the alerts banner.
+// Rows are templates of their own so the live feed can push a single changed
+// row; every row element carries an id the pushed fragments target.
+var fragments = template.Must(template.New("fragments").Parse(`
+{{define "stale-badge"}}
+<div class="stale-badge" hx-get="{{.Path}}" hx-target="{{.Target}}" hx-trigger="every {{.RetryEvery}}s" hx-swap="innerHTML">
+    {{.Title}} are unavailable; showing the last data received, stale since {{.Since.Format "15:04:05"}}.
+</div>
+{{end}}
+
+{{define "unavailable"}}
+<div class="unavailable" hx-get="{{.Path}}" hx-target="{{.Target}}" hx-trigger="every {{.RetryEvery}}s" hx-swap="innerHTML">
+    {{.Title}} are unavailable right now. Retrying every {{.RetryEvery}} seconds.
+</div>
+{{end}}
+{{define "alerts-banner"}}
+{{range .}}
+<div class="alert-banner alert-{{.Severity}}">
+    <strong>{{.RuleName}}</strong> at {{.StationID}}: {{.Metric}} {{.Value}}{{.Unit}}{{if .Message}} ({{.Message}}){{end}}{{if .FiredAt}}, firing since {{.FiredAt.Format "15:04:05"}}{{end}}
+    <button hx-post="/acknowledge-alert/{{.ID}}"
+            hx-target="#alerts-banner"
+            hx-swap="innerHTML"
+            class="btn">Acknowledge</button>
+</div>
+{{end}}
+{{end}}
+
+{{define "traffic-light-fields"}}
+        <strong>Location:</strong> {{.Location}}, <strong>Color:</strong> {{.Color}}
+        <div style="display: inline-block; margin-left: 10px;">
+            <select name="color"
+                    hx-put="/update-traffic-light/{{.ID}}"
+                    hx-target="#traffic-lights"
+                    hx-trigger="change"
+                    hx-include="this">
+                <option value="red" {{if eq .Color "red"}}selected{{end}}>Red</option>
+                <option value="yellow" {{if eq .Color "yellow"}}selected{{end}}>Yellow</option>
+                <option value="green" {{if eq .Color "green"}}selected{{end}}>Green</option>
+            </select>
+            <button hx-delete="/delete-traffic-light/{{.ID}}"
+                    hx-target="#traffic-lights"
+                    hx-swap="innerHTML"
+                    class="btn btn-delete">Delete</button>
+        </div>
+{{end}}
+
+{{define "traffic-light-row"}}
+    <div class="traffic-light" id="traffic-light-{{.ID}}">{{template "traffic-light-fields" .}}</div>
+{{end}}
+
+{{define "traffic-lights"}}
+<div class="traffic-lights" id="traffic-lights-list">
+    {{range .}}{{template "traffic-light-row" .}}{{end}}
+</div>
+{{if not .}}
+<div class="no-lights" id="traffic-lights-empty">
+    There are no traffic lights.
+</div>
+{{end}}
+{{end}}
+
+{{define "weather-entry-fields"}}
+        <strong>Location:</strong> {{.Location}}, <strong>Temperature:</strong> {{.Temperature}}{{.TemperatureUnit}}, <strong>Description:</strong> {{.Description}}
+        <div style="display: inline-block; margin-left: 10px;">
+            <button hx-delete="/delete-weather-entry/{{.ID}}"
+                    hx-target="#weather-entries"
+                    hx-swap="innerHTML"
+                    class="btn btn-delete">Delete</button>
+        </div>
+{{end}}
+
+{{define "weather-entry-row"}}
+    <div class="weather-entry" id="weather-entry-{{.ID}}">{{template "weather-entry-fields" .}}</div>
+{{end}}
+
+{{define "weather-entries"}}
+<div class="weather-entries" id="weather-entries-list">
+    {{range .}}{{template "weather-entry-row" .}}{{end}}
+</div>
+{{if not .}}
+<div class="no-entries" id="weather-entries-empty">
+    There are no weather entries.
+</div>
+{{end}}
+{{end}}
+
+{{define "parking-spot-fields"}}
+        <strong>Location:</strong> {{.Location}}, <strong>Availability:</strong> {{if .Availability}}Available{{else}}Unavailable{{end}}
+        <div style="display: inline-block; margin-left: 10px;">
+            <select name="availability"
+                    hx-put="/update-parking-spot/{{.ID}}"
+                    hx-target="#parking-spots"
+                    hx-trigger="change"
+                    hx-include="this">
+                <option value="true" {{if .Availability}}selected{{end}}>Available</option>
+                <option value="false" {{if not .Availability}}selected{{end}}>Unavailable</option>
+            </select>
+            <button hx-delete="/delete-parking-spot/{{.ID}}"
+                    hx-target="#parking-spots"
+                    hx-swap="innerHTML"
+                    class="btn btn-delete">Delete</button>
+        </div>
+{{end}}
+
+{{define "parking-spot-row"}}
+    <div class="parking-spot" id="parking-spot-{{.ID}}">{{template "parking-spot-fields" .}}</div>
+{{end}}
+
+{{define "parking-spots"}}
+<div class="parking-spots" id="parking-spots-list">
+    {{range .}}{{template "parking-spot-row" .}}{{end}}
+</div>
+{{if not .}}
+<div class="no-spots" id="parking-spots-empty">
+    There are no parking spots.
+</div>
+{{end}}
+{{end}}
+`))
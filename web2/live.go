@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"metagrid/client"
+)
+
+const (
+	liveClientQueue = 32
+	liveKeepAlive   = 15 * time.Second
+	// The services send a keep-alive every 15 seconds; a feed silent for
+	// longer than this is assumed dead and reconnected.
+	liveFeedIdle   = 45 * time.Second
+	liveMaxBackoff = 30 * time.Second
+)
+
+// liveFeed is a service's change event stream that web2 follows.
+type liveFeed struct {
+	Name string
+	URL  string
+}
+
+var liveFeeds = []liveFeed{
+	{"traffic", "http://traffic.localhost/events"},
+	{"weather", "http://weather.localhost/events"},
+	{"parking", "http://parking.localhost/events"},
+}
+
+// ServiceEvent is a change published on a service's /events stream.
+type ServiceEvent struct {
+	ID         int64           `json:"id"`
+	Resource   string          `json:"resource"`
+	Action     string          `json:"action"`
+	ResourceID string          `json:"resource_id"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// liveMessage is an event pushed to browsers. "rows" carries out-of-band
+// swaps of changed rows; the others name triggers that reload a fragment.
+type liveMessage struct {
+	Event string
+	Data  string
+}
+
+type liveClient struct {
+	units string
+	ch    chan liveMessage
+}
+
+// liveHub follows the services' event streams and pushes re-rendered rows to
+// the dashboards connected to /live.
+type liveHub struct {
+	app *App
+	// streams has no timeout or resilience policy, as streams stay open; it
+	// only finds an instance of the service.
+	streams *http.Client
+
+	mu      sync.Mutex
+	clients map[*liveClient]struct{}
+}
+
+func newLiveHub(app *App) *liveHub {
+	return &liveHub{
+		app:     app,
+		streams: &http.Client{Transport: app.discovery},
+		clients: map[*liveClient]struct{}{},
+	}
+}
+
+// run follows every feed in the background.
+func (h *liveHub) run() {
+	for _, feed := range liveFeeds {
+		go h.follow(feed)
+	}
+}
+
+func (h *liveHub) subscribe(units string) *liveClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := &liveClient{units: units, ch: make(chan liveMessage, liveClientQueue)}
+	h.clients[c] = struct{}{}
+	return c
+}
+
+func (h *liveHub) unsubscribe(c *liveClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.ch)
+	}
+}
+
+func (h *liveHub) clientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// unitsInUse returns the unit systems of the connected dashboards, so weather
+// rows are rendered once per unit system rather than once per dashboard.
+func (h *liveHub) unitsInUse() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	seen := map[string]bool{}
+	var units []string
+	for c := range h.clients {
+		if !seen[c.units] {
+			seen[c.units] = true
+			units = append(units, c.units)
+		}
+	}
+	return units
+}
+
+// send queues m for the dashboards using units, or for all when units is
+// empty. A dashboard too far behind is disconnected; it reloads its lists
+// when the browser reconnects.
+func (h *liveHub) send(units string, m liveMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if units != "" && c.units != units {
+			continue
+		}
+		select {
+		case c.ch <- m:
+		default:
+			delete(h.clients, c)
+			close(c.ch)
+		}
+	}
+}
+
+// follow keeps a feed connected, resuming from the last event it saw.
+func (h *liveHub) follow(feed liveFeed) {
+	lastID := ""
+	backoff := time.Second
+	for {
+		connected := time.Now()
+		err := h.consume(feed, &lastID)
+		slog.Warn("Live feed disconnected", "feed", feed.Name, "error", err)
+		if time.Since(connected) > liveMaxBackoff {
+			backoff = time.Second
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, liveMaxBackoff)
+	}
+}
+
+// consume reads one connection of a feed until it fails or goes quiet.
+func (h *liveHub) consume(feed liveFeed, lastID *string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(liveFeedIdle, cancel)
+	defer idle.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastID != "" {
+		req.Header.Set("Last-Event-ID", *lastID)
+	}
+
+	resp, err := h.streams.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var id string
+	var data []string
+	for scanner.Scan() {
+		idle.Reset(liveFeedIdle)
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				h.dispatch(strings.Join(data, "\n"))
+			}
+			if id != "" {
+				*lastID = id
+			}
+			id, data = "", nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// dispatch turns a service event into messages for the dashboards.
+func (h *liveHub) dispatch(data string) {
+	var e ServiceEvent
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		slog.Error("Error decoding live event", "error", err)
+		return
+	}
+	id, err := strconv.Atoi(e.ResourceID)
+
+	switch {
+	case e.Resource == "traffic_light" && err == nil:
+		h.app.trafficLights.invalidate()
+		var light client.TrafficLight
+		if e.Action != "deleted" && json.Unmarshal(e.Data, &light) != nil {
+			return
+		}
+		h.pushRow("", "traffic-lights", "traffic-light", e.Action, id, light)
+	case e.Resource == "parking_spot" && err == nil:
+		h.app.parkingSpots.invalidate()
+		var spot client.ParkingSpot
+		if e.Action != "deleted" && json.Unmarshal(e.Data, &spot) != nil {
+			return
+		}
+		h.pushRow("", "parking-spots", "parking-spot", e.Action, id, spot)
+	case e.Resource == "observation" && err == nil:
+		h.app.weatherEntries.invalidate()
+		h.app.alerts.invalidate()
+		for _, units := range h.unitsInUse() {
+			var entry *client.WeatherEntry
+			if e.Action != "deleted" {
+				// Entries are shown in each dashboard's units, so read them
+				// back rather than converting the event's canonical values.
+				entry, err = h.app.api.Weather.GetEntry(context.Background(), id, client.Units(units))
+				if errors.Is(err, client.ErrNotFound) {
+					// Observations without a temperature are not weather entries.
+					continue
+				}
+				if err != nil {
+					slog.Error("Error fetching weather entry", "id", id, "error", err)
+					continue
+				}
+			}
+			h.pushRow(units, "weather-entries", "weather-entry", e.Action, id, entry)
+		}
+		// New observations can fire alerts.
+		h.send("", liveMessage{Event: "alerts-changed"})
+	case e.Resource == "station":
+		// Renaming or deleting a station touches many entries at once.
+		h.app.weatherEntries.invalidate()
+		h.send("", liveMessage{Event: "weather-refresh"})
+	}
+}
+
+// pushRow sends the out-of-band swaps for a changed row. Created rows replace
+// any copy the dashboard already loaded before being appended, so a row is
+// never shown twice.
+func (h *liveHub) pushRow(units, list, prefix, action string, id int, row any) {
+	var b strings.Builder
+	remove := fmt.Sprintf(`<span hx-swap-oob="outerHTML:#%s-%d"></span>`, prefix, id)
+	switch action {
+	case "created":
+		b.WriteString(remove)
+		fmt.Fprintf(&b, `<div hx-swap-oob="beforeend:#%s-list">`, list)
+		if err := fragments.ExecuteTemplate(&b, prefix+"-row", row); err != nil {
+			slog.Error("Error executing template", "error", err)
+			return
+		}
+		fmt.Fprintf(&b, `</div><span hx-swap-oob="outerHTML:#%s-empty"></span>`, list)
+	case "updated":
+		fmt.Fprintf(&b, `<div hx-swap-oob="innerHTML:#%s-%d">`, prefix, id)
+		if err := fragments.ExecuteTemplate(&b, prefix+"-fields", row); err != nil {
+			slog.Error("Error executing template", "error", err)
+			return
+		}
+		b.WriteString(`</div>`)
+	case "deleted":
+		b.WriteString(remove)
+	default:
+		return
+	}
+	h.send(units, liveMessage{Event: "rows", Data: b.String()})
+}
+
+func writeLiveMessage(w io.Writer, m liveMessage) error {
+	if _, err := fmt.Fprintf(w, "event: %s\n", m.Event); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(m.Data, "\n") {
+		if _, err := fmt.Fprintf(w, "data: %s\n", line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, "\n")
+	return err
+}
+
+// Live Updates Handler
+func (app *App) liveHandler(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// The server's write timeout would otherwise end the stream.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.ErrorContext(r.Context(), "Error clearing write deadline", "error", err)
+	}
+
+	client := app.live.subscribe(unitsPreference(r))
+	defer app.live.unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Changes made before this connection subscribed were not pushed to the
+	// browser, so have it reload every list once it is listening.
+	if writeLiveMessage(w, liveMessage{Event: "refresh"}) != nil || rc.Flush() != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(liveKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case m, ok := <-client.ch:
+			if !ok {
+				return
+			}
+			if writeLiveMessage(w, m) != nil || rc.Flush() != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
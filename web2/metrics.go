@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var latencyBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by route pattern, method and status.",
+	}, []string{"route", "method", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests, by route pattern, method and status.",
+		Buckets: latencyBuckets,
+	}, []string{"route", "method", "status"})
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "upstream_requests_total",
+		Help: "Requests made to the services, by service, method and status; failed requests have status \"error\".",
+	}, []string{"service", "method", "status"})
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "upstream_request_duration_seconds",
+		Help:    "Time taken by requests to the services, by service, method and status.",
+		Buckets: latencyBuckets,
+	}, []string{"service", "method", "status"})
+	upstreamRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "upstream_retries_total",
+		Help: "Requests to the services retried after a failed attempt, by service.",
+	}, []string{"service"})
+	upstreamRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "upstream_rejections_total",
+		Help: "Requests to the services failed without being sent, by service and reason (bulkhead or circuit_open).",
+	}, []string{"service", "reason"})
+	upstreamCircuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "upstream_circuit_state",
+		Help: "State of each service's circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, []string{"service"})
+	staleSnapshots = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "stale_snapshots_served_total",
+		Help: "Dashboard fragments rendered from a stale snapshot because the service failed, by snapshot.",
+	}, []string{"snapshot"})
+)
+
+// registerMetrics registers web2's collectors with the default registry,
+// which already exports Go runtime and process metrics.
+func (app *App) registerMetrics() {
+	prometheus.MustRegister(
+		httpRequests,
+		httpDuration,
+		upstreamRequests,
+		upstreamDuration,
+		upstreamRetries,
+		upstreamRejections,
+		upstreamCircuitState,
+		staleSnapshots,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "live_dashboards",
+			Help: "Dashboards connected to /live.",
+		}, func() float64 { return float64(app.live.clientCount()) }),
+	)
+}
+
+// statusRecorder remembers the status written through it. Unwrap lets
+// http.ResponseController reach the Flusher of the underlying writer.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// instrumentRequests records the count and latency of every request, labelled
+// with the ServeMux pattern that matched it.
+func instrumentRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		labels := prometheus.Labels{"route": route, "method": r.Method, "status": strconv.Itoa(rec.status)}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// instrumentedTransport records the requests web2 makes to the services.
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	labels := prometheus.Labels{
+		"service": strings.TrimSuffix(req.URL.Hostname(), ".localhost"),
+		"method":  req.Method,
+		"status":  status,
+	}
+	upstreamRequests.With(labels).Inc()
+	upstreamDuration.With(labels).Observe(time.Since(start).Seconds())
+	return resp, err
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// consulCheckTTL is how long Consul waits for a heartbeat before marking
+	// an instance critical, and Traefik stops routing to it.
+	consulCheckTTL = "10s"
+	// consulDeregisterAfter removes an instance that died without
+	// deregistering, once its check has been critical this long.
+	consulDeregisterAfter = "1m"
+	consulWatchWait       = 30 * time.Second
+	consulWatchRetry      = 2 * time.Second
+)
+
+// Instance is one running instance of a service.
+type Instance struct {
+	ID      string   `yaml:"id"`
+	Service string   `yaml:"-"`
+	Address string   `yaml:"address"`
+	Port    int      `yaml:"port"`
+	Tags    []string `yaml:"tags"`
+}
+
+// HostPort is the instance's address as used in a URL. An instance without a
+// port is reached on the scheme's default port.
+func (i Instance) HostPort() string {
+	if i.Port == 0 {
+		return i.Address
+	}
+	return net.JoinHostPort(i.Address, strconv.Itoa(i.Port))
+}
+
+// Registry records the running instances of each service. An instance
+// registers and deregisters itself; clients resolve or watch the instances of
+// the services they call.
+type Registry interface {
+	Register(ctx context.Context, instance Instance) error
+	Deregister(ctx context.Context, id string) error
+	// Resolve returns the instances of service ready to serve requests.
+	Resolve(ctx context.Context, service string) ([]Instance, error)
+	// Watch sends the ready instances of service now and whenever they
+	// change. The channel is closed once ctx is done.
+	Watch(ctx context.Context, service string) (<-chan []Instance, error)
+}
+
+// heartbeater is implemented by registries that only keep an instance in
+// rotation while it keeps reporting that it is ready.
+type heartbeater interface {
+	Heartbeat(ctx context.Context, id string, ready bool, output string) error
+}
+
+// newRegistry returns the registry named by REGISTRY. By default web2 reaches
+// each service through Traefik, at the host metagrid.sh adds to /etc/hosts.
+// consul resolves the instances from the catalog, which needs web2 on the
+// services' network; static reads them from the YAML file named by
+// REGISTRY_FILE, so web2 can call services run without Consul or Traefik;
+// memory keeps them in the process, for tests.
+func newRegistry() (Registry, error) {
+	switch kind := os.Getenv("REGISTRY"); kind {
+	case "", "traefik":
+		return newStaticRegistry(map[string][]Instance{
+			"traffic": {{Address: "traffic.localhost"}},
+			"weather": {{Address: "weather.localhost"}},
+			"parking": {{Address: "parking.localhost"}},
+		})
+	case "consul":
+		return newConsulRegistry("localhost:8500")
+	case "static":
+		file := os.Getenv("REGISTRY_FILE")
+		if file == "" {
+			file = "registry.yaml"
+		}
+		return loadStaticRegistry(file)
+	case "memory":
+		return newMemoryRegistry(), nil
+	default:
+		return nil, fmt.Errorf("unknown registry %q: use traefik, consul, static or memory", kind)
+	}
+}
+
+// consulRegistry registers instances with the local Consul agent under a TTL
+// check and resolves the instances whose checks pass.
+type consulRegistry struct {
+	client *consulapi.Client
+}
+
+// newConsulRegistry connects to the agent at CONSUL_HTTP_ADDR, or
+// defaultAddress.
+func newConsulRegistry(defaultAddress string) (*consulRegistry, error) {
+	config := consulapi.DefaultConfig()
+	if os.Getenv("CONSUL_HTTP_ADDR") == "" {
+		config.Address = defaultAddress
+	}
+	client, err := consulapi.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &consulRegistry{client: client}, nil
+}
+
+func consulCheckID(id string) string {
+	return "service:" + id
+}
+
+func (r *consulRegistry) Register(ctx context.Context, instance Instance) error {
+	reg := &consulapi.AgentServiceRegistration{
+		ID:      instance.ID,
+		Name:    instance.Service,
+		Address: instance.Address,
+		Port:    instance.Port,
+		Tags:    instance.Tags,
+		Check: &consulapi.AgentServiceCheck{
+			CheckID:                        consulCheckID(instance.ID),
+			TTL:                            consulCheckTTL,
+			DeregisterCriticalServiceAfter: consulDeregisterAfter,
+		},
+	}
+	return r.client.Agent().ServiceRegisterOpts(reg, consulapi.ServiceRegisterOpts{}.WithContext(ctx))
+}
+
+func (r *consulRegistry) Deregister(ctx context.Context, id string) error {
+	return r.client.Agent().ServiceDeregisterOpts(id, (&consulapi.QueryOptions{}).WithContext(ctx))
+}
+
+// Heartbeat sets the instance's TTL check. It fails if the agent no longer
+// knows the check, as happens when the dev agent restarts.
+func (r *consulRegistry) Heartbeat(ctx context.Context, id string, ready bool, output string) error {
+	status := consulapi.HealthPassing
+	if !ready {
+		status = consulapi.HealthCritical
+	}
+	return r.client.Agent().UpdateTTLOpts(consulCheckID(id), output, status, (&consulapi.QueryOptions{}).WithContext(ctx))
+}
+
+func (r *consulRegistry) Resolve(ctx context.Context, service string) ([]Instance, error) {
+	instances, _, err := r.passing(service, (&consulapi.QueryOptions{}).WithContext(ctx))
+	return instances, err
+}
+
+// Watch follows the service's passing instances with blocking queries,
+// retrying while the agent is unreachable.
+func (r *consulRegistry) Watch(ctx context.Context, service string) (<-chan []Instance, error) {
+	instances, index, err := r.passing(service, (&consulapi.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	updates := make(chan []Instance, 1)
+	updates <- instances
+
+	go func() {
+		defer close(updates)
+		for ctx.Err() == nil {
+			q := (&consulapi.QueryOptions{WaitIndex: index, WaitTime: consulWatchWait}).WithContext(ctx)
+			next, nextIndex, err := r.passing(service, q)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+				case <-time.After(consulWatchRetry):
+				}
+				continue
+			}
+			if nextIndex == index {
+				continue
+			}
+			// The index also changes, going backwards, when the agent
+			// restarts; the next query waits from the new one.
+			index = nextIndex
+			sendLatest(updates, next)
+		}
+	}()
+	return updates, nil
+}
+
+func (r *consulRegistry) passing(service string, q *consulapi.QueryOptions) ([]Instance, uint64, error) {
+	entries, meta, err := r.client.Health().Service(service, "", true, q)
+	if err != nil {
+		return nil, 0, err
+	}
+	instances := make([]Instance, 0, len(entries))
+	for _, entry := range entries {
+		address := entry.Service.Address
+		if address == "" {
+			address = entry.Node.Address
+		}
+		instances = append(instances, Instance{
+			ID:      entry.Service.ID,
+			Service: entry.Service.Service,
+			Address: address,
+			Port:    entry.Service.Port,
+			Tags:    entry.Service.Tags,
+		})
+	}
+	return instances, meta.LastIndex, nil
+}
+
+// staticRegistry serves a fixed list of instances read from a YAML file that
+// maps service names to their instances:
+//
+//	services:
+//	  traffic:
+//	    - address: localhost
+//	      port: 5050
+//
+// Registering and deregistering do nothing: the file is the source of truth.
+type staticRegistry struct {
+	services map[string][]Instance
+}
+
+func loadStaticRegistry(file string) (*staticRegistry, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Services map[string][]Instance `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return newStaticRegistry(config.Services)
+}
+
+func newStaticRegistry(services map[string][]Instance) (*staticRegistry, error) {
+	r := &staticRegistry{services: make(map[string][]Instance, len(services))}
+	for service, instances := range services {
+		for i, instance := range instances {
+			if instance.Address == "" {
+				return nil, fmt.Errorf("instance %d of %s has no address", i+1, service)
+			}
+			instance.Service = service
+			if instance.ID == "" {
+				instance.ID = fmt.Sprintf("%s-%d", service, i+1)
+			}
+			r.services[service] = append(r.services[service], instance)
+		}
+	}
+	return r, nil
+}
+
+func (r *staticRegistry) Register(ctx context.Context, instance Instance) error {
+	return nil
+}
+
+func (r *staticRegistry) Deregister(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *staticRegistry) Resolve(ctx context.Context, service string) ([]Instance, error) {
+	return slices.Clone(r.services[service]), nil
+}
+
+func (r *staticRegistry) Watch(ctx context.Context, service string) (<-chan []Instance, error) {
+	updates := make(chan []Instance, 1)
+	updates <- slices.Clone(r.services[service])
+	go func() {
+		<-ctx.Done()
+		close(updates)
+	}()
+	return updates, nil
+}
+
+// memoryRegistry keeps the instances in the process, for running without any
+// registry and for tests that stand up instances themselves. Registered
+// instances are ready until a heartbeat says otherwise.
+type memoryRegistry struct {
+	mu        sync.Mutex
+	instances map[string]memoryInstance
+	watchers  map[string]map[chan []Instance]bool
+}
+
+type memoryInstance struct {
+	Instance
+	ready bool
+}
+
+func newMemoryRegistry() *memoryRegistry {
+	return &memoryRegistry{
+		instances: make(map[string]memoryInstance),
+		watchers:  make(map[string]map[chan []Instance]bool),
+	}
+}
+
+func (r *memoryRegistry) Register(ctx context.Context, instance Instance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.instances[instance.ID]; ok && old.Service != instance.Service {
+		delete(r.instances, instance.ID)
+		r.notify(old.Service)
+	}
+	r.instances[instance.ID] = memoryInstance{Instance: instance, ready: true}
+	r.notify(instance.Service)
+	return nil
+}
+
+func (r *memoryRegistry) Deregister(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if instance, ok := r.instances[id]; ok {
+		delete(r.instances, id)
+		r.notify(instance.Service)
+	}
+	return nil
+}
+
+func (r *memoryRegistry) Heartbeat(ctx context.Context, id string, ready bool, output string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instance, ok := r.instances[id]
+	if !ok {
+		return fmt.Errorf("instance %s is not registered", id)
+	}
+	if instance.ready != ready {
+		instance.ready = ready
+		r.instances[id] = instance
+		r.notify(instance.Service)
+	}
+	return nil
+}
+
+func (r *memoryRegistry) Resolve(ctx context.Context, service string) ([]Instance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ready(service), nil
+}
+
+func (r *memoryRegistry) Watch(ctx context.Context, service string) (<-chan []Instance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	updates := make(chan []Instance, 1)
+	updates <- r.ready(service)
+	if r.watchers[service] == nil {
+		r.watchers[service] = make(map[chan []Instance]bool)
+	}
+	r.watchers[service][updates] = true
+
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.watchers[service], updates)
+		close(updates)
+	}()
+	return updates, nil
+}
+
+// ready returns the ready instances of service, ordered by ID. r.mu must be
+// held.
+func (r *memoryRegistry) ready(service string) []Instance {
+	var instances []Instance
+	for _, instance := range r.instances {
+		if instance.Service == service && instance.ready {
+			instances = append(instances, instance.Instance)
+		}
+	}
+	slices.SortFunc(instances, func(a, b Instance) int { return strings.Compare(a.ID, b.ID) })
+	return instances
+}
+
+// notify sends the ready instances of service to its watchers. r.mu must be
+// held.
+func (r *memoryRegistry) notify(service string) {
+	for updates := range r.watchers[service] {
+		sendLatest(updates, r.ready(service))
+	}
+}
+
+// sendLatest replaces any list a slow watcher has not read yet, so watchers
+// always receive the most recent one without blocking the sender.
+func sendLatest(updates chan []Instance, instances []Instance) {
+	for {
+		select {
+		case updates <- instances:
+			return
+		default:
+		}
+		select {
+		case <-updates:
+		default:
+		}
+	}
+}
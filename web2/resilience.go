@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// upstreamPolicy is how web2 protects itself from a slow or failing service.
+type upstreamPolicy struct {
+	// Deadline bounds a request to the service, retries included.
+	Deadline time.Duration
+	// Routes overrides Deadline for paths starting with a prefix.
+	Routes map[string]time.Duration
+	// Retries is how many more attempts an idempotent request gets after a
+	// connection error or a 502, 503 or 504.
+	Retries int
+	// RetryBackoff is the longest wait before the first retry; it doubles
+	// for each retry and the actual wait is a random fraction of it.
+	RetryBackoff time.Duration
+	// MaxConcurrent is the bulkhead: requests to the service in flight at
+	// once. Requests beyond it fail at once instead of queueing.
+	MaxConcurrent int
+	// FailureThreshold consecutive failures open the circuit breaker, which
+	// then fails requests at once for OpenFor before letting a single probe
+	// through.
+	FailureThreshold int
+	OpenFor          time.Duration
+}
+
+var defaultPolicy = upstreamPolicy{
+	Deadline:         3 * time.Second,
+	Retries:          2,
+	RetryBackoff:     100 * time.Millisecond,
+	MaxConcurrent:    32,
+	FailureThreshold: 5,
+	OpenFor:          10 * time.Second,
+}
+
+// upstreamPolicies returns the policy of each service web2 calls.
+func upstreamPolicies() map[string]upstreamPolicy {
+	weather := defaultPolicy
+	// Forecasts fit a model to the station's history.
+	weather.Routes = map[string]time.Duration{"/weather/forecast": 8 * time.Second}
+	return map[string]upstreamPolicy{
+		"traffic": defaultPolicy,
+		"weather": weather,
+		"parking": defaultPolicy,
+	}
+}
+
+// deadline returns the deadline of a request for path, from the longest
+// matching route prefix.
+func (p upstreamPolicy) deadline(path string) time.Duration {
+	deadline, longest := p.Deadline, -1
+	for prefix, d := range p.Routes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			deadline, longest = d, len(prefix)
+		}
+	}
+	return deadline
+}
+
+var (
+	errCircuitOpen  = errors.New("circuit breaker open")
+	errBulkheadFull = errors.New("too many requests in flight")
+)
+
+// Circuit breaker states.
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// upstream is the resilience state of one service.
+type upstream struct {
+	name   string
+	policy upstreamPolicy
+	slots  chan struct{} // bulkhead; a request holds a slot while in flight
+
+	mu        sync.Mutex
+	state     string
+	failures  int // consecutive
+	openedAt  time.Time
+	probing   bool // a half-open probe is in flight
+	lastError string
+	lastErrAt time.Time
+	// Totals for the status page.
+	requests       int64
+	retries        int64
+	rejected       int64 // by the bulkhead
+	shortCircuited int64 // by the open breaker
+}
+
+func newUpstream(name string, policy upstreamPolicy) *upstream {
+	return &upstream{
+		name:   name,
+		policy: policy,
+		slots:  make(chan struct{}, policy.MaxConcurrent),
+		state:  circuitClosed,
+	}
+}
+
+// allow reports whether the breaker lets a request through. Once OpenFor has
+// passed an open breaker turns half-open and lets one probe through; its
+// result closes the breaker or opens it again.
+func (u *upstream) allow() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.state == circuitOpen && time.Since(u.openedAt) >= u.policy.OpenFor {
+		u.setState(circuitHalfOpen)
+	}
+	switch {
+	case u.state == circuitOpen, u.state == circuitHalfOpen && u.probing:
+		u.shortCircuited++
+		upstreamRejections.WithLabelValues(u.name, "circuit_open").Inc()
+		return fmt.Errorf("%s: %w", u.name, errCircuitOpen)
+	case u.state == circuitHalfOpen:
+		u.probing = true
+	}
+	return nil
+}
+
+// record counts the outcome of an attempt allowed by the breaker. An attempt
+// the caller abandoned says nothing about the service and is not counted.
+func (u *upstream) record(failure error, abandoned bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.probing = false
+	if abandoned {
+		return
+	}
+	if failure == nil {
+		u.failures = 0
+		u.setState(circuitClosed)
+		return
+	}
+	u.failures++
+	u.lastError, u.lastErrAt = failure.Error(), time.Now()
+	if u.state == circuitHalfOpen || u.failures >= u.policy.FailureThreshold {
+		u.openedAt = time.Now()
+		u.setState(circuitOpen)
+	}
+}
+
+// setState changes the breaker's state; u.mu must be held.
+func (u *upstream) setState(state string) {
+	if u.state == state {
+		return
+	}
+	u.state = state
+	upstreamCircuitState.WithLabelValues(u.name).Set(circuitStateValue[state])
+}
+
+var circuitStateValue = map[string]float64{circuitClosed: 0, circuitHalfOpen: 1, circuitOpen: 2}
+
+// acquire takes a bulkhead slot without waiting.
+func (u *upstream) acquire() error {
+	select {
+	case u.slots <- struct{}{}:
+		return nil
+	default:
+		u.mu.Lock()
+		u.rejected++
+		u.mu.Unlock()
+		upstreamRejections.WithLabelValues(u.name, "bulkhead").Inc()
+		return fmt.Errorf("%s: %w", u.name, errBulkheadFull)
+	}
+}
+
+func (u *upstream) release() {
+	<-u.slots
+}
+
+// retryBackoff is a random wait of up to RetryBackoff doubled for each
+// earlier retry.
+func (u *upstream) retryBackoff(retry int) time.Duration {
+	return time.Duration(rand.Int64N(int64(u.policy.RetryBackoff) << retry))
+}
+
+// resilientTransport applies each service's upstreamPolicy to the requests
+// sent to it. Requests to other hosts pass through untouched.
+type resilientTransport struct {
+	upstreams map[string]*upstream
+	next      http.RoundTripper
+}
+
+func newResilientTransport(policies map[string]upstreamPolicy, next http.RoundTripper) *resilientTransport {
+	t := &resilientTransport{upstreams: make(map[string]*upstream, len(policies)), next: next}
+	for name, policy := range policies {
+		t.upstreams[name] = newUpstream(name, policy)
+		upstreamCircuitState.WithLabelValues(name).Set(0)
+	}
+	return t
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, _ := strings.CutSuffix(req.URL.Hostname(), ".localhost")
+	u, ok := t.upstreams[service]
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	if err := u.acquire(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), u.policy.deadline(req.URL.Path))
+	done := func() {
+		cancel()
+		u.release()
+	}
+	u.mu.Lock()
+	u.requests++
+	u.mu.Unlock()
+
+	for retry := 0; ; retry++ {
+		if err := u.allow(); err != nil {
+			done()
+			return nil, err
+		}
+		attempt := req.Clone(ctx)
+		if retry > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				done()
+				return nil, err
+			}
+			attempt.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attempt)
+		failure := attemptFailure(resp, err)
+		u.record(failure, req.Context().Err() != nil)
+
+		if failure == nil || retry >= u.policy.Retries || !canRetry(req, resp, err) {
+			if err != nil {
+				done()
+				return nil, err
+			}
+			resp.Body = &trackedBody{ReadCloser: resp.Body, done: done}
+			return resp, nil
+		}
+
+		wait := u.retryBackoff(retry)
+		if deadline, _ := ctx.Deadline(); time.Until(deadline) <= wait {
+			// No time left for another attempt: return this one's result.
+			if err != nil {
+				done()
+				return nil, err
+			}
+			resp.Body = &trackedBody{ReadCloser: resp.Body, done: done}
+			return resp, nil
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		u.mu.Lock()
+		u.retries++
+		u.mu.Unlock()
+		upstreamRetries.WithLabelValues(u.name).Inc()
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			done()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// attemptFailure returns why an attempt counts as a failure of the service:
+// a connection error, a timeout or a server error.
+func attemptFailure(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// canRetry reports whether a failed attempt may be repeated: the method must
+// be idempotent, the body replayable, and the failure one another instance
+// could avoid. A timeout is not retried, as it has used up the deadline.
+func canRetry(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// UpstreamStatus is the state of one service's policies, for the status page.
+type UpstreamStatus struct {
+	Name           string
+	Policy         upstreamPolicy
+	RouteDeadlines []string
+	State          string
+	Failures       int
+	OpenedAt       time.Time
+	InFlight       int
+	Requests       int64
+	Retries        int64
+	Rejected       int64
+	ShortCircuited int64
+	LastError      string
+	LastErrorAt    time.Time
+	Instances      []InstanceStatus
+	DiscoveryError string
+}
+
+// InstanceStatus is a discovered instance of a service and the requests web2
+// has in flight to it.
+type InstanceStatus struct {
+	ID          string
+	Address     string
+	Outstanding int64
+}
+
+func (t *resilientTransport) status() []UpstreamStatus {
+	statuses := make([]UpstreamStatus, 0, len(t.upstreams))
+	for _, u := range t.upstreams {
+		u.mu.Lock()
+		s := UpstreamStatus{
+			Name:           u.name,
+			Policy:         u.policy,
+			State:          u.state,
+			Failures:       u.failures,
+			OpenedAt:       u.openedAt,
+			InFlight:       len(u.slots),
+			Requests:       u.requests,
+			Retries:        u.retries,
+			Rejected:       u.rejected,
+			ShortCircuited: u.shortCircuited,
+			LastError:      u.lastError,
+			LastErrorAt:    u.lastErrAt,
+		}
+		u.mu.Unlock()
+		for prefix, d := range u.policy.Routes {
+			s.RouteDeadlines = append(s.RouteDeadlines, fmt.Sprintf("%s: %s", prefix, d))
+		}
+		sort.Strings(s.RouteDeadlines)
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Status Page Handler
+func (app *App) statusHandler(w http.ResponseWriter, r *http.Request) {
+	upstreams := app.upstreams.status()
+	for i := range upstreams {
+		instances, err := app.discovery.status(upstreams[i].Name)
+		upstreams[i].Instances = instances
+		if err != nil {
+			upstreams[i].DiscoveryError = err.Error()
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if err := app.templates.ExecuteTemplate(w, "status.html", upstreams); err != nil {
+		slog.ErrorContext(r.Context(), "Error executing template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
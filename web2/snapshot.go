@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// A snapshot younger than snapshotFresh is served without asking the
+	// service. Up to snapshotRevalidate older it is still served, while a
+	// background refresh fetches the next one.
+	snapshotFresh      = 2 * time.Second
+	snapshotRevalidate = 30 * time.Second
+	// staleRetry is how often a section showing a stale snapshot or no data
+	// asks again.
+	staleRetry = 10 * time.Second
+)
+
+// snapshotCache keeps the last list fetched successfully from a service for
+// each key, such as the units the list is shown in, so a dashboard section
+// can still be shown while the service is down.
+type snapshotCache[T any] struct {
+	name  string
+	fetch func(ctx context.Context, key string) (T, error)
+
+	mu      sync.Mutex
+	entries map[string]*snapshot[T]
+}
+
+type snapshot[T any] struct {
+	value      T
+	fetchedAt  time.Time
+	failing    bool // the last fetch failed
+	invalid    bool // changed since fetched; the next get fetches again
+	refreshing bool
+}
+
+func newSnapshotCache[T any](name string, fetch func(ctx context.Context, key string) (T, error)) *snapshotCache[T] {
+	return &snapshotCache[T]{name: name, fetch: fetch, entries: make(map[string]*snapshot[T])}
+}
+
+// get returns the list for key and the time it was fetched. While the
+// service fails it returns the last snapshot with stale set; it returns an
+// error only when there is no snapshot yet. A snapshot is served at once
+// while it is fresh, while it is being revalidated, and while the service is
+// failing, in which case a background fetch checks whether it is back.
+func (c *snapshotCache[T]) get(ctx context.Context, key string) (value T, fetchedAt time.Time, stale bool, err error) {
+	c.mu.Lock()
+	if s, ok := c.entries[key]; ok && !s.invalid {
+		age := time.Since(s.fetchedAt)
+		switch {
+		case s.failing:
+			c.refreshLocked(ctx, key, s)
+			c.mu.Unlock()
+			staleSnapshots.WithLabelValues(c.name).Inc()
+			return s.value, s.fetchedAt, true, nil
+		case age < snapshotFresh:
+			c.mu.Unlock()
+			return s.value, s.fetchedAt, false, nil
+		case age < snapshotFresh+snapshotRevalidate:
+			c.refreshLocked(ctx, key, s)
+			c.mu.Unlock()
+			return s.value, s.fetchedAt, false, nil
+		}
+	}
+	c.mu.Unlock()
+
+	value, err = c.fetch(ctx, key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.store(key, value, err)
+	if err == nil {
+		return value, s.fetchedAt, false, nil
+	}
+	if !ok {
+		return value, time.Time{}, false, err
+	}
+	slog.WarnContext(ctx, "Serving stale snapshot", "snapshot", c.name, "fetched_at", s.fetchedAt, "error", err)
+	staleSnapshots.WithLabelValues(c.name).Inc()
+	return s.value, s.fetchedAt, true, nil
+}
+
+// refreshLocked fetches key again in the background unless a fetch is already
+// running; c.mu must be held. The fetch keeps ctx's values, such as the
+// trace, but not its cancellation, as it outlives the request.
+func (c *snapshotCache[T]) refreshLocked(ctx context.Context, key string, s *snapshot[T]) {
+	if s.refreshing {
+		return
+	}
+	s.refreshing = true
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		value, err := c.fetch(ctx, key)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		s.refreshing = false
+		c.store(key, value, err)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to refresh snapshot", "snapshot", c.name, "error", err)
+		}
+	}()
+}
+
+// store records the outcome of a fetch and returns the snapshot now held for
+// key, if any; c.mu must be held.
+func (c *snapshotCache[T]) store(key string, value T, err error) (*snapshot[T], bool) {
+	s, ok := c.entries[key]
+	if err != nil {
+		if ok {
+			s.failing = true
+		}
+		return s, ok
+	}
+	if !ok {
+		s = &snapshot[T]{}
+		c.entries[key] = s
+	}
+	s.value, s.fetchedAt, s.failing, s.invalid = value, time.Now(), false, false
+	return s, true
+}
+
+// invalidate makes the next get of every key fetch again, after web2 or the
+// service's event stream has changed the list. The snapshots are kept in
+// case that fetch fails.
+func (c *snapshotCache[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.entries {
+		s.invalid = true
+	}
+}
+
+// section is a dashboard section filled by a fragment, named in the notices
+// shown when its service is down. The notices ask for the fragment again
+// every staleRetry, so the section recovers once the service does.
+type section struct {
+	Title  string
+	Path   string
+	Target string
+}
+
+var (
+	trafficLightsSection  = section{"Traffic lights", "/traffic-lights", "#traffic-lights"}
+	weatherEntriesSection = section{"Weather entries", "/weather-entries", "#weather-entries"}
+	alertsSection         = section{"Weather alerts", "/alerts-banner", "#alerts-banner"}
+	parkingSpotsSection   = section{"Parking spots", "/parking-spots", "#parking-spots"}
+)
+
+type notice struct {
+	section
+	Since      time.Time
+	RetryEvery int // seconds
+}
+
+// writeStaleBadge marks the fragment that follows as a snapshot of staleSince,
+// and does nothing when the data is current.
+func writeStaleBadge(w http.ResponseWriter, r *http.Request, s section, staleSince time.Time) {
+	if staleSince.IsZero() {
+		return
+	}
+	if err := fragments.ExecuteTemplate(w, "stale-badge", notice{s, staleSince, int(staleRetry / time.Second)}); err != nil {
+		slog.ErrorContext(r.Context(), "Error executing template", "error", err)
+	}
+}
+
+// writeUnavailable stands in for a section's fragment when its service is
+// down and there is no snapshot to show. It is sent with 200, as htmx does not
+// swap in error responses and the section would keep its old content with no
+// sign that it is out of date.
+func writeUnavailable(w http.ResponseWriter, r *http.Request, s section) {
+	if err := fragments.ExecuteTemplate(w, "unavailable", notice{section: s, RetryEvery: int(staleRetry / time.Second)}); err != nil {
+		slog.ErrorContext(r.Context(), "Error executing template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// renderSection writes a section's fragment from the data the JSON API serves
+// for it, with a stale badge or, when there is no data, the unavailable
+// notice.
+func renderSection[T any](w http.ResponseWriter, r *http.Request, s section, fragment string, data SectionData[T]) {
+	if data.Status == sectionUnavailable {
+		writeUnavailable(w, r, s)
+		return
+	}
+	if data.StaleSince != nil {
+		writeStaleBadge(w, r, s, *data.StaleSince)
+	}
+	if err := fragments.ExecuteTemplate(w, fragment, data.Items); err != nil {
+		slog.ErrorContext(r.Context(), "Error executing template", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+// setupTracing installs the global tracer provider and W3C trace context
+// propagation. Spans are sent to the collector named by
+// OTEL_EXPORTER_OTLP_ENDPOINT, or appended as JSON to the file named by
+// TRACES_FILE for offline analysis. With neither set, spans are still created
+// so the services receive traceparent headers, but nothing is exported.
+func setupTracing() error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	res, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName("web2")),
+		resource.WithFromEnv(),
+		resource.WithHost(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return err
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	switch {
+	case os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "":
+		exporter, err := otlptracehttp.New(context.Background())
+		if err != nil {
+			return err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case os.Getenv("TRACES_FILE") != "":
+		f, err := os.OpenFile(os.Getenv("TRACES_FILE"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(opts...))
+	return nil
+}
+
+// traceRequests starts a server span for each request, continuing the trace
+// in the caller's traceparent header.
+func traceRequests(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string { return r.Method }),
+	)
+}
+
+// nameSpans renames the request's span after the ServeMux pattern that
+// matched it. ServeMux sets the pattern on the request it is given, so this
+// must wrap the mux directly.
+func nameSpans(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if r.Pattern != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + r.Pattern)
+			span.SetAttributes(semconv.HTTPRoute(r.Pattern))
+		}
+	})
+}
+
+// tracedTransport wraps next so each call to a service gets a client span and
+// a traceparent header.
+func tracedTransport(next http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(next,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Host
+		}),
+	)
+}
+
+type requestIDKey struct{}
+
+// requestID returns the ID assigned to the request ctx belongs to.
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// assignRequestID gives every request an ID, keeping one sent by the caller.
+// The ID is returned in the X-Request-Id header, recorded on the request's
+// span, added to its log lines, forwarded to the services and appended to
+// plain-text error messages.
+func assignRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(requestIDHeader, id)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.request.id", id))
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+		next.ServeHTTP(&requestIDWriter{ResponseWriter: w, id: id}, r)
+	})
+}
+
+// forwardRequestID sends the ID of the request a call is made for to the
+// service, whose logs then carry the same ID.
+type forwardRequestID struct {
+	next http.RoundTripper
+}
+
+func (t forwardRequestID) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := requestID(req.Context()); id != "" && req.Header.Get(requestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(requestIDHeader, id)
+	}
+	return t.next.RoundTrip(req)
+}
+
+type requestIDWriter struct {
+	http.ResponseWriter
+	id     string
+	status int
+	tagged bool
+}
+
+func (w *requestIDWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write tags the single line written by http.Error.
+func (w *requestIDWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status < 400 || w.tagged || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		return w.ResponseWriter.Write(b)
+	}
+	w.tagged = true
+	if _, err := fmt.Fprintf(w.ResponseWriter, "%s (request ID %s)\n", strings.TrimSuffix(string(b), "\n"), w.id); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *requestIDWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}